@@ -164,5 +164,22 @@ func TestAggregations(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Empty include for termsAggs",
+			Aggregate(
+				TermsAgg("categories", "categories").
+					Include([]string{}...),
+			),
+			map[string]interface{}{
+				"aggs": map[string]interface{}{
+					"categories": map[string]interface{}{
+						"terms": map[string]interface{}{
+							"field":   "categories",
+							"include": []string{},
+						},
+					},
+				},
+			},
+		},
 	})
 }
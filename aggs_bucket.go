@@ -4,6 +4,8 @@
 
 package osquery
 
+import "encoding/json"
+
 //----------------------------------------------------------------------------//
 
 // TermsAggregation represents an aggregation of type "terms", as described in
@@ -92,7 +94,7 @@ func (agg *TermsAggregation) Map() map[string]interface{} {
 	}
 
 	if agg.include != nil {
-		if len(agg.include) <= 1 {
+		if len(agg.include) == 1 {
 			innerMap["include"] = agg.include[0]
 		} else {
 			innerMap["include"] = agg.include
@@ -112,3 +114,423 @@ func (agg *TermsAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *TermsAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *TermsAggregation) validate(path string, errs *ValidationErrors) {
+	if agg.include != nil && len(agg.include) == 0 {
+		errs.add(joinPath(path, "terms"), "include must not be empty")
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. The results of sub-aggregations are available through the Aggs
+// field of each bucket.
+func (agg *TermsAggregation) Result(aggs AggregationResults) (*TermsResult, error) {
+	var res TermsResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// HistogramResult is the result of a "histogram" or "date_histogram"
+// aggregation. Buckets are always returned in order, even when the aggregation
+// is keyed.
+type HistogramResult struct {
+	Buckets []Bucket
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (res *HistogramResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Buckets json.RawMessage `json:"buckets"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	buckets, err := decodeBuckets(raw.Buckets, setBucketKey)
+	if err != nil {
+		return err
+	}
+	res.Buckets = buckets
+	return nil
+}
+
+//----------------------------------------------------------------------------//
+
+// DateHistogramAggregation represents an aggregation of type "date_histogram",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/bucket/date-histogram/
+type DateHistogramAggregation struct {
+	name             string
+	field            string
+	calendarInterval string
+	fixedInterval    string
+	timeZone         string
+	offset           string
+	format           string
+	minDocCount      *uint64
+	extendedBounds   map[string]interface{}
+	hardBounds       map[string]interface{}
+	keyed            *bool
+	missing          interface{}
+	order            map[string]string
+	aggs             []Aggregation
+}
+
+// DateHistogramAgg creates a new aggregation of type "date_histogram" on the
+// provided field. Either CalendarInterval or FixedInterval should be set.
+func DateHistogramAgg(name, field string) *DateHistogramAggregation {
+	return &DateHistogramAggregation{
+		name:  name,
+		field: field,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *DateHistogramAggregation) Name() string {
+	return agg.name
+}
+
+// CalendarInterval sets a calendar-aware interval for the buckets, such as
+// "day", "1M" or "quarter".
+func (agg *DateHistogramAggregation) CalendarInterval(interval string) *DateHistogramAggregation {
+	agg.calendarInterval = interval
+	return agg
+}
+
+// FixedInterval sets a fixed interval for the buckets, such as "90s" or "12h".
+func (agg *DateHistogramAggregation) FixedInterval(interval string) *DateHistogramAggregation {
+	agg.fixedInterval = interval
+	return agg
+}
+
+// TimeZone sets the time zone used for bucketing and rounding.
+func (agg *DateHistogramAggregation) TimeZone(zone string) *DateHistogramAggregation {
+	agg.timeZone = zone
+	return agg
+}
+
+// Offset shifts the start of each bucket by the provided duration, such as
+// "+6h".
+func (agg *DateHistogramAggregation) Offset(offset string) *DateHistogramAggregation {
+	agg.offset = offset
+	return agg
+}
+
+// Format sets the date format used for the "key_as_string" of each bucket.
+func (agg *DateHistogramAggregation) Format(format string) *DateHistogramAggregation {
+	agg.format = format
+	return agg
+}
+
+// MinDocCount sets the minimum number of documents a bucket must contain to be
+// returned.
+func (agg *DateHistogramAggregation) MinDocCount(count uint64) *DateHistogramAggregation {
+	agg.minDocCount = &count
+	return agg
+}
+
+// ExtendedBounds forces buckets to be returned between min and max, even if
+// they are empty. Values can be dates, date math expressions or epoch
+// milliseconds.
+func (agg *DateHistogramAggregation) ExtendedBounds(min, max interface{}) *DateHistogramAggregation {
+	agg.extendedBounds = map[string]interface{}{
+		"min": min,
+		"max": max,
+	}
+	return agg
+}
+
+// HardBounds limits the buckets to the range between min and max.
+func (agg *DateHistogramAggregation) HardBounds(min, max interface{}) *DateHistogramAggregation {
+	agg.hardBounds = map[string]interface{}{
+		"min": min,
+		"max": max,
+	}
+	return agg
+}
+
+// Keyed sets whether buckets should be returned as a map keyed by the bucket
+// key rather than as a list.
+func (agg *DateHistogramAggregation) Keyed(b bool) *DateHistogramAggregation {
+	agg.keyed = &b
+	return agg
+}
+
+// Missing sets the value to use for documents missing a value for the field.
+func (agg *DateHistogramAggregation) Missing(val interface{}) *DateHistogramAggregation {
+	agg.missing = val
+	return agg
+}
+
+// Order sets the sort for the buckets.
+func (agg *DateHistogramAggregation) Order(order map[string]string) *DateHistogramAggregation {
+	agg.order = order
+	return agg
+}
+
+// Aggs sets sub-aggregations for the aggregation.
+func (agg *DateHistogramAggregation) Aggs(aggs ...Aggregation) *DateHistogramAggregation {
+	agg.aggs = aggs
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *DateHistogramAggregation) Map() map[string]interface{} {
+	innerMap := map[string]interface{}{
+		"field": agg.field,
+	}
+
+	if agg.calendarInterval != "" {
+		innerMap["calendar_interval"] = agg.calendarInterval
+	}
+	if agg.fixedInterval != "" {
+		innerMap["fixed_interval"] = agg.fixedInterval
+	}
+	if agg.timeZone != "" {
+		innerMap["time_zone"] = agg.timeZone
+	}
+	if agg.offset != "" {
+		innerMap["offset"] = agg.offset
+	}
+	if agg.format != "" {
+		innerMap["format"] = agg.format
+	}
+	if agg.minDocCount != nil {
+		innerMap["min_doc_count"] = *agg.minDocCount
+	}
+	if agg.extendedBounds != nil {
+		innerMap["extended_bounds"] = agg.extendedBounds
+	}
+	if agg.hardBounds != nil {
+		innerMap["hard_bounds"] = agg.hardBounds
+	}
+	if agg.keyed != nil {
+		innerMap["keyed"] = *agg.keyed
+	}
+	if agg.missing != nil {
+		innerMap["missing"] = agg.missing
+	}
+	if agg.order != nil {
+		innerMap["order"] = agg.order
+	}
+
+	outerMap := map[string]interface{}{
+		"date_histogram": innerMap,
+	}
+	if len(agg.aggs) > 0 {
+		subAggs := make(map[string]map[string]interface{})
+		for _, sub := range agg.aggs {
+			subAggs[sub.Name()] = sub.Map()
+		}
+		outerMap["aggs"] = subAggs
+	}
+
+	return outerMap
+}
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *DateHistogramAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *DateHistogramAggregation) validate(path string, errs *ValidationErrors) {
+	switch {
+	case agg.calendarInterval == "" && agg.fixedInterval == "":
+		errs.add(joinPath(path, "date_histogram"), "calendar_interval or fixed_interval is required")
+	case agg.calendarInterval != "" && agg.fixedInterval != "":
+		errs.add(joinPath(path, "date_histogram"), "calendar_interval and fixed_interval cannot both be set")
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. The results of sub-aggregations are available through the Aggs
+// field of each bucket.
+func (agg *DateHistogramAggregation) Result(aggs AggregationResults) (*HistogramResult, error) {
+	var res HistogramResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// HistogramAggregation represents an aggregation of type "histogram", as
+// described in
+// https://opensearch.org/docs/latest/aggregations/bucket/histogram/
+type HistogramAggregation struct {
+	name           string
+	field          string
+	interval       float64
+	offset         *float64
+	minDocCount    *uint64
+	extendedBounds map[string]float64
+	hardBounds     map[string]float64
+	keyed          *bool
+	missing        interface{}
+	order          map[string]string
+	aggs           []Aggregation
+}
+
+// HistogramAgg creates a new aggregation of type "histogram" on the provided
+// field, with buckets of the provided interval.
+func HistogramAgg(name, field string, interval float64) *HistogramAggregation {
+	return &HistogramAggregation{
+		name:     name,
+		field:    field,
+		interval: interval,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *HistogramAggregation) Name() string {
+	return agg.name
+}
+
+// Interval sets the size of each bucket.
+func (agg *HistogramAggregation) Interval(interval float64) *HistogramAggregation {
+	agg.interval = interval
+	return agg
+}
+
+// Offset shifts the bucket boundaries by the provided value.
+func (agg *HistogramAggregation) Offset(offset float64) *HistogramAggregation {
+	agg.offset = &offset
+	return agg
+}
+
+// MinDocCount sets the minimum number of documents a bucket must contain to be
+// returned.
+func (agg *HistogramAggregation) MinDocCount(count uint64) *HistogramAggregation {
+	agg.minDocCount = &count
+	return agg
+}
+
+// ExtendedBounds forces buckets to be returned between min and max, even if
+// they are empty.
+func (agg *HistogramAggregation) ExtendedBounds(min, max float64) *HistogramAggregation {
+	agg.extendedBounds = map[string]float64{
+		"min": min,
+		"max": max,
+	}
+	return agg
+}
+
+// HardBounds limits the buckets to the range between min and max.
+func (agg *HistogramAggregation) HardBounds(min, max float64) *HistogramAggregation {
+	agg.hardBounds = map[string]float64{
+		"min": min,
+		"max": max,
+	}
+	return agg
+}
+
+// Keyed sets whether buckets should be returned as a map keyed by the bucket
+// key rather than as a list.
+func (agg *HistogramAggregation) Keyed(b bool) *HistogramAggregation {
+	agg.keyed = &b
+	return agg
+}
+
+// Missing sets the value to use for documents missing a value for the field.
+func (agg *HistogramAggregation) Missing(val interface{}) *HistogramAggregation {
+	agg.missing = val
+	return agg
+}
+
+// Order sets the sort for the buckets.
+func (agg *HistogramAggregation) Order(order map[string]string) *HistogramAggregation {
+	agg.order = order
+	return agg
+}
+
+// Aggs sets sub-aggregations for the aggregation.
+func (agg *HistogramAggregation) Aggs(aggs ...Aggregation) *HistogramAggregation {
+	agg.aggs = aggs
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *HistogramAggregation) Map() map[string]interface{} {
+	innerMap := map[string]interface{}{
+		"field":    agg.field,
+		"interval": agg.interval,
+	}
+
+	if agg.offset != nil {
+		innerMap["offset"] = *agg.offset
+	}
+	if agg.minDocCount != nil {
+		innerMap["min_doc_count"] = *agg.minDocCount
+	}
+	if agg.extendedBounds != nil {
+		innerMap["extended_bounds"] = agg.extendedBounds
+	}
+	if agg.hardBounds != nil {
+		innerMap["hard_bounds"] = agg.hardBounds
+	}
+	if agg.keyed != nil {
+		innerMap["keyed"] = *agg.keyed
+	}
+	if agg.missing != nil {
+		innerMap["missing"] = agg.missing
+	}
+	if agg.order != nil {
+		innerMap["order"] = agg.order
+	}
+
+	outerMap := map[string]interface{}{
+		"histogram": innerMap,
+	}
+	if len(agg.aggs) > 0 {
+		subAggs := make(map[string]map[string]interface{})
+		for _, sub := range agg.aggs {
+			subAggs[sub.Name()] = sub.Map()
+		}
+		outerMap["aggs"] = subAggs
+	}
+
+	return outerMap
+}
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *HistogramAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *HistogramAggregation) validate(path string, errs *ValidationErrors) {
+	if agg.interval <= 0 {
+		errs.add(joinPath(path, "histogram"), "interval must be positive, got %v", agg.interval)
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. The results of sub-aggregations are available through the Aggs
+// field of each bucket.
+func (agg *HistogramAggregation) Result(aggs AggregationResults) (*HistogramResult, error) {
+	var res HistogramResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
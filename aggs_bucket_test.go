@@ -0,0 +1,154 @@
+package osquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestHistogramAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"date_histogram agg: simple",
+			DateHistogramAgg("per_day", "@timestamp").CalendarInterval("day"),
+			map[string]interface{}{
+				"date_histogram": map[string]interface{}{
+					"field":             "@timestamp",
+					"calendar_interval": "day",
+				},
+			},
+		},
+		{
+			"date_histogram agg: all options",
+			DateHistogramAgg("per_hour", "@timestamp").
+				FixedInterval("12h").
+				TimeZone("Europe/Paris").
+				Offset("+6h").
+				Format("yyyy-MM-dd").
+				MinDocCount(0).
+				ExtendedBounds("now-7d/d", "now/d").
+				HardBounds("now-30d/d", "now/d").
+				Keyed(true).
+				Missing("2024-01-01").
+				Order(map[string]string{"_key": "desc"}).
+				Aggs(Avg("avg_took", "took")),
+			map[string]interface{}{
+				"date_histogram": map[string]interface{}{
+					"field":          "@timestamp",
+					"fixed_interval": "12h",
+					"time_zone":      "Europe/Paris",
+					"offset":         "+6h",
+					"format":         "yyyy-MM-dd",
+					"min_doc_count":  0,
+					"extended_bounds": map[string]interface{}{
+						"min": "now-7d/d",
+						"max": "now/d",
+					},
+					"hard_bounds": map[string]interface{}{
+						"min": "now-30d/d",
+						"max": "now/d",
+					},
+					"keyed":   true,
+					"missing": "2024-01-01",
+					"order": map[string]interface{}{
+						"_key": "desc",
+					},
+				},
+				"aggs": map[string]interface{}{
+					"avg_took": map[string]interface{}{
+						"avg": map[string]interface{}{
+							"field": "took",
+						},
+					},
+				},
+			},
+		},
+		{
+			"histogram agg: simple",
+			HistogramAgg("prices", "price", 50),
+			map[string]interface{}{
+				"histogram": map[string]interface{}{
+					"field":    "price",
+					"interval": 50,
+				},
+			},
+		},
+		{
+			"histogram agg: all options",
+			HistogramAgg("prices", "price", 10).
+				Interval(50).
+				Offset(5).
+				MinDocCount(1).
+				ExtendedBounds(0, 500).
+				HardBounds(0, 1000).
+				Keyed(false).
+				Missing(0).
+				Aggs(Sum("total", "price")),
+			map[string]interface{}{
+				"histogram": map[string]interface{}{
+					"field":         "price",
+					"interval":      50,
+					"offset":        5,
+					"min_doc_count": 1,
+					"extended_bounds": map[string]interface{}{
+						"min": 0,
+						"max": 500,
+					},
+					"hard_bounds": map[string]interface{}{
+						"min": 0,
+						"max": 1000,
+					},
+					"keyed":   false,
+					"missing": 0,
+				},
+				"aggs": map[string]interface{}{
+					"total": map[string]interface{}{
+						"sum": map[string]interface{}{
+							"field": "price",
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestHistogramResults(t *testing.T) {
+	perDay := DateHistogramAgg("per_day", "@timestamp").CalendarInterval("day")
+	prices := HistogramAgg("prices", "price", 50).Keyed(true)
+	total := Sum("total", "price")
+	prices.Aggs(total)
+
+	aggs, err := ParseAggregations(json.RawMessage(`{
+		"per_day": {
+			"buckets": [
+				{"key_as_string": "2024-01-01", "key": 1704067200000, "doc_count": 3},
+				{"key_as_string": "2024-01-02", "key": 1704153600000, "doc_count": 0}
+			]
+		},
+		"prices": {
+			"buckets": {
+				"100.0": {"key": 100.0, "doc_count": 2, "total": {"value": 230}},
+				"0.0": {"key": 0.0, "doc_count": 1, "total": {"value": 20}}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	days, err := perDay.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(days.Buckets))
+	assert.Equal(t, "2024-01-01", days.Buckets[0].KeyAsString)
+	assert.Equal(t, int64(3), days.Buckets[0].DocCount)
+
+	histogram, err := prices.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(histogram.Buckets))
+	assert.Equal(t, 100.0, histogram.Buckets[0].Key)
+	assert.Equal(t, 0.0, histogram.Buckets[1].Key)
+
+	sum, err := total.Result(histogram.Buckets[0].Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 230.0, *sum.Value)
+}
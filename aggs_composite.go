@@ -0,0 +1,422 @@
+package osquery
+
+import (
+	"context"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+)
+
+// CompositeAggregation represents an aggregation of type "composite", as
+// described in
+// https://opensearch.org/docs/latest/aggregations/bucket/composite/
+type CompositeAggregation struct {
+	name    string
+	size    *uint64
+	sources []*CompositeSource
+	after   map[string]interface{}
+	aggs    []Aggregation
+}
+
+// CompositeAgg creates a new aggregation of type "composite" with the provided
+// sources. Buckets are built from every combination of the values of the
+// sources, in the order the sources are provided.
+func CompositeAgg(name string, sources ...*CompositeSource) *CompositeAggregation {
+	return &CompositeAggregation{
+		name:    name,
+		sources: sources,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *CompositeAggregation) Name() string {
+	return agg.name
+}
+
+// Sources appends one or more value sources to the aggregation.
+func (agg *CompositeAggregation) Sources(sources ...*CompositeSource) *CompositeAggregation {
+	agg.sources = append(agg.sources, sources...)
+	return agg
+}
+
+// Size sets the number of buckets to return per page.
+func (agg *CompositeAggregation) Size(size uint64) *CompositeAggregation {
+	agg.size = &size
+	return agg
+}
+
+// After sets the key of the bucket after which to start returning buckets,
+// usually the "after_key" of a previous response.
+func (agg *CompositeAggregation) After(after map[string]interface{}) *CompositeAggregation {
+	agg.after = after
+	return agg
+}
+
+// Aggs sets sub-aggregations for the aggregation.
+func (agg *CompositeAggregation) Aggs(aggs ...Aggregation) *CompositeAggregation {
+	agg.aggs = aggs
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *CompositeAggregation) Map() map[string]interface{} {
+	sources := make([]map[string]interface{}, len(agg.sources))
+	for i, src := range agg.sources {
+		sources[i] = src.Map()
+	}
+
+	innerMap := map[string]interface{}{
+		"sources": sources,
+	}
+	if agg.size != nil {
+		innerMap["size"] = *agg.size
+	}
+	if agg.after != nil {
+		innerMap["after"] = agg.after
+	}
+
+	outerMap := map[string]interface{}{
+		"composite": innerMap,
+	}
+	if len(agg.aggs) > 0 {
+		subAggs := make(map[string]map[string]interface{})
+		for _, sub := range agg.aggs {
+			subAggs[sub.Name()] = sub.Map()
+		}
+		outerMap["aggs"] = subAggs
+	}
+
+	return outerMap
+}
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *CompositeAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *CompositeAggregation) validate(path string, errs *ValidationErrors) {
+	if len(agg.sources) == 0 {
+		errs.add(joinPath(path, "composite"), "at least one source is required")
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *CompositeAggregation) Result(aggs AggregationResults) (*CompositeResult, error) {
+	var res CompositeResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// CompositeMissingOrder is an enumeration type representing supported values
+// for a composite source's "missing_order" parameter.
+type CompositeMissingOrder string
+
+const (
+	// MissingOrderDefault sorts the missing bucket according to the order of
+	// the source.
+	MissingOrderDefault CompositeMissingOrder = "default"
+
+	// MissingOrderFirst places the missing bucket first.
+	MissingOrderFirst CompositeMissingOrder = "first"
+
+	// MissingOrderLast places the missing bucket last.
+	MissingOrderLast CompositeMissingOrder = "last"
+)
+
+// CompositeSource represents a single value source of a composite
+// aggregation. Sources of type "terms", "histogram" and "date_histogram" are
+// supported; options that do not apply to the type of the source are ignored.
+type CompositeSource struct {
+	name             string
+	sType            string
+	field            string
+	interval         float64
+	calendarInterval string
+	fixedInterval    string
+	timeZone         string
+	format           string
+	offset           string
+	order            Order
+	missingBucket    *bool
+	missingOrder     CompositeMissingOrder
+}
+
+// TermsSource creates a new composite source of type "terms" on the provided
+// field.
+func TermsSource(name, field string) *CompositeSource {
+	return &CompositeSource{
+		name:  name,
+		sType: "terms",
+		field: field,
+	}
+}
+
+// HistogramSource creates a new composite source of type "histogram" on the
+// provided field, with buckets of the provided interval.
+func HistogramSource(name, field string, interval float64) *CompositeSource {
+	return &CompositeSource{
+		name:     name,
+		sType:    "histogram",
+		field:    field,
+		interval: interval,
+	}
+}
+
+// DateHistogramSource creates a new composite source of type "date_histogram"
+// on the provided field. Either CalendarInterval or FixedInterval should be
+// set.
+func DateHistogramSource(name, field string) *CompositeSource {
+	return &CompositeSource{
+		name:  name,
+		sType: "date_histogram",
+		field: field,
+	}
+}
+
+// Name returns the name of the source.
+func (src *CompositeSource) Name() string {
+	return src.name
+}
+
+// CalendarInterval sets a calendar-aware interval for a "date_histogram"
+// source.
+func (src *CompositeSource) CalendarInterval(interval string) *CompositeSource {
+	src.calendarInterval = interval
+	return src
+}
+
+// FixedInterval sets a fixed interval for a "date_histogram" source.
+func (src *CompositeSource) FixedInterval(interval string) *CompositeSource {
+	src.fixedInterval = interval
+	return src
+}
+
+// TimeZone sets the time zone of a "date_histogram" source.
+func (src *CompositeSource) TimeZone(zone string) *CompositeSource {
+	src.timeZone = zone
+	return src
+}
+
+// Format sets the date format of a "date_histogram" source's keys.
+func (src *CompositeSource) Format(format string) *CompositeSource {
+	src.format = format
+	return src
+}
+
+// Offset shifts the buckets of a "date_histogram" source by the provided
+// duration.
+func (src *CompositeSource) Offset(offset string) *CompositeSource {
+	src.offset = offset
+	return src
+}
+
+// Order sets the sort order of the source's values.
+func (src *CompositeSource) Order(order Order) *CompositeSource {
+	src.order = order
+	return src
+}
+
+// MissingBucket sets whether documents without a value for the source's field
+// should be returned in a bucket with a null key.
+func (src *CompositeSource) MissingBucket(b bool) *CompositeSource {
+	src.missingBucket = &b
+	return src
+}
+
+// MissingOrder sets where the missing bucket should be placed.
+func (src *CompositeSource) MissingOrder(order CompositeMissingOrder) *CompositeSource {
+	src.missingOrder = order
+	return src
+}
+
+// Map returns a map representation of the source, thus implementing the
+// Mappable interface.
+func (src *CompositeSource) Map() map[string]interface{} {
+	innerMap := map[string]interface{}{
+		"field": src.field,
+	}
+
+	switch src.sType {
+	case "histogram":
+		innerMap["interval"] = src.interval
+	case "date_histogram":
+		if src.calendarInterval != "" {
+			innerMap["calendar_interval"] = src.calendarInterval
+		}
+		if src.fixedInterval != "" {
+			innerMap["fixed_interval"] = src.fixedInterval
+		}
+		if src.timeZone != "" {
+			innerMap["time_zone"] = src.timeZone
+		}
+		if src.format != "" {
+			innerMap["format"] = src.format
+		}
+		if src.offset != "" {
+			innerMap["offset"] = src.offset
+		}
+	}
+
+	if src.order != "" {
+		innerMap["order"] = src.order
+	}
+	if src.missingBucket != nil {
+		innerMap["missing_bucket"] = *src.missingBucket
+	}
+	if src.missingOrder != "" {
+		innerMap["missing_order"] = src.missingOrder
+	}
+
+	return map[string]interface{}{
+		src.name: map[string]interface{}{
+			src.sType: innerMap,
+		},
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// CompositeResult is the result of a "composite" aggregation. AfterKey is nil
+// once all buckets have been returned.
+type CompositeResult struct {
+	AfterKey map[string]interface{} `json:"after_key"`
+	Buckets  []CompositeBucket      `json:"buckets"`
+}
+
+// CompositeBucket is a single bucket of a composite aggregation. Key maps the
+// name of each source to its value for the bucket.
+type CompositeBucket struct {
+	Key      map[string]interface{}
+	DocCount int64
+	Aggs     AggregationResults
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *CompositeBucket) UnmarshalJSON(data []byte) error {
+	fields, aggs, err := splitBucket(data)
+	if err != nil {
+		return err
+	}
+
+	b.Aggs = aggs
+	if err := decodeField(fields, "key", &b.Key); err != nil {
+		return err
+	}
+	return decodeField(fields, "doc_count", &b.DocCount)
+}
+
+//----------------------------------------------------------------------------//
+
+// CompositeIterator pages through all buckets of a composite aggregation,
+// following the "after_key" of each response until the aggregation is
+// exhausted. Pages are only fetched when the buckets of the previous page have
+// been consumed.
+//
+//	it := agg.Iterate(osquery.Search().Size(0).Aggs(agg), client, nil)
+//	for it.Next(ctx) {
+//	    bucket := it.Bucket()
+//	    // ...
+//	}
+//	if err := it.Err(); err != nil {
+//	    // ...
+//	}
+type CompositeIterator struct {
+	agg     *CompositeAggregation
+	req     *SearchRequest
+	client  *opensearch.Client
+	options *SearchOptions
+
+	buckets []CompositeBucket
+	bucket  CompositeBucket
+	done    bool
+	err     error
+}
+
+// Iterate returns an iterator over all buckets of the aggregation. The provided
+// search request must include the aggregation at its top level. The iterator
+// updates the aggregation's "after" value as it moves from page to page.
+func (agg *CompositeAggregation) Iterate(
+	req *SearchRequest,
+	client *opensearch.Client,
+	options *SearchOptions,
+) *CompositeIterator {
+	return &CompositeIterator{
+		agg:     agg,
+		req:     req,
+		client:  client,
+		options: options,
+	}
+}
+
+// Next advances the iterator to the next bucket, fetching the next page of
+// buckets if necessary. It returns false when all buckets have been returned
+// or an error occurred, which is then available through Err.
+func (it *CompositeIterator) Next(ctx context.Context) bool {
+	for len(it.buckets) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch(ctx)
+	}
+
+	it.bucket = it.buckets[0]
+	it.buckets = it.buckets[1:]
+	return true
+}
+
+// fetch executes the search request for the next page of buckets.
+func (it *CompositeIterator) fetch(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+
+	res, err := it.req.Run(ctx, it.client, it.options)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	aggs, err := ParseAggregations(res.Aggregations)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	page, err := it.agg.Result(aggs)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.buckets = page.Buckets
+	if len(page.Buckets) == 0 || page.AfterKey == nil {
+		it.done = true
+		return
+	}
+	it.agg.After(page.AfterKey)
+}
+
+// Bucket returns the current bucket.
+func (it *CompositeIterator) Bucket() CompositeBucket {
+	return it.bucket
+}
+
+// AfterKey returns the key of the current bucket, which can be passed to After
+// to resume iterating from this point later on.
+func (it *CompositeIterator) AfterKey() map[string]interface{} {
+	return it.bucket.Key
+}
+
+// Err returns the first error encountered by the iterator, if any.
+func (it *CompositeIterator) Err() error {
+	return it.err
+}
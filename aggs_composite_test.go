@@ -0,0 +1,133 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestCompositeAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"composite agg: all source types",
+			CompositeAgg("combinations",
+				TermsSource("product", "product.keyword").
+					Order(OrderAsc).
+					MissingBucket(true).
+					MissingOrder(MissingOrderLast),
+				HistogramSource("price", "price", 50),
+			).
+				Sources(
+					DateHistogramSource("day", "@timestamp").
+						CalendarInterval("1d").
+						TimeZone("UTC").
+						Format("yyyy-MM-dd").
+						Order(OrderDesc),
+				).
+				Size(100).
+				After(map[string]interface{}{"product": "a", "price": 0, "day": "2024-01-01"}).
+				Aggs(Sum("total", "price")),
+			map[string]interface{}{
+				"composite": map[string]interface{}{
+					"sources": []map[string]interface{}{
+						{
+							"product": map[string]interface{}{
+								"terms": map[string]interface{}{
+									"field":          "product.keyword",
+									"order":          "asc",
+									"missing_bucket": true,
+									"missing_order":  "last",
+								},
+							},
+						},
+						{
+							"price": map[string]interface{}{
+								"histogram": map[string]interface{}{
+									"field":    "price",
+									"interval": 50,
+								},
+							},
+						},
+						{
+							"day": map[string]interface{}{
+								"date_histogram": map[string]interface{}{
+									"field":             "@timestamp",
+									"calendar_interval": "1d",
+									"time_zone":         "UTC",
+									"format":            "yyyy-MM-dd",
+									"order":             "desc",
+								},
+							},
+						},
+					},
+					"size": 100,
+					"after": map[string]interface{}{
+						"product": "a",
+						"price":   0,
+						"day":     "2024-01-01",
+					},
+				},
+				"aggs": map[string]interface{}{
+					"total": map[string]interface{}{
+						"sum": map[string]interface{}{
+							"field": "price",
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestCompositeIterator(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"aggregations": {"products": {
+			"after_key": {"product": "b"},
+			"buckets": [
+				{"key": {"product": "a"}, "doc_count": 2},
+				{"key": {"product": "b"}, "doc_count": 1}
+			]
+		}}}`,
+		`{"aggregations": {"products": {
+			"after_key": {"product": "c"},
+			"buckets": [
+				{"key": {"product": null}, "doc_count": 5}
+			]
+		}}}`,
+		`{"aggregations": {"products": {"buckets": []}}}`,
+	)
+
+	agg := CompositeAgg("products", TermsSource("product", "product").MissingBucket(true)).Size(2)
+	it := agg.Iterate(Search().Size(0).Aggs(agg), client, nil)
+
+	var keys []interface{}
+	var total int64
+	for it.Next(context.Background()) {
+		keys = append(keys, it.Bucket().Key["product"])
+		total += it.Bucket().DocCount
+	}
+	assert.Nil(t, it.Err())
+	assert.DeepEqual(t, []interface{}{"a", "b", nil}, keys)
+	assert.Equal(t, int64(8), total)
+
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t,
+		`{"aggs":{"products":{"composite":{"size":2,"sources":[{"product":{"terms":{"field":"product","missing_bucket":true}}}]}}},"size":0}`,
+		(*requests)[0].body,
+	)
+	assert.Equal(t,
+		`{"aggs":{"products":{"composite":{"after":{"product":"b"},"size":2,"sources":[{"product":{"terms":{"field":"product","missing_bucket":true}}}]}}},"size":0}`,
+		(*requests)[1].body,
+	)
+}
+
+func TestCompositeIteratorError(t *testing.T) {
+	client, _ := newTestClient(t, `{"error":{"type":"search_phase_execution_exception","reason":"all shards failed"},"status":400}`)
+
+	agg := CompositeAgg("products", TermsSource("product", "product"))
+	it := agg.Iterate(Search().Aggs(agg), client, nil)
+
+	assert.False(t, it.Next(context.Background()))
+	assert.NotNil(t, it.Err())
+}
@@ -51,3 +51,30 @@ func (agg *FilterAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *FilterAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *FilterAggregation) validate(path string, errs *ValidationErrors) {
+	if agg.filter == nil {
+		errs.add(path, "filter is required")
+	} else {
+		validateNode(agg.filter, joinPath(path, "filter"), errs)
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. The results of sub-aggregations are available through the Aggs
+// field of the returned bucket.
+func (agg *FilterAggregation) Result(aggs AggregationResults) (*Bucket, error) {
+	var res Bucket
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -4,7 +4,11 @@
 
 package osquery
 
-import "github.com/fatih/structs"
+import (
+	"encoding/json"
+
+	"github.com/fatih/structs"
+)
 
 // BaseAgg contains several fields that are common for all aggregation types.
 type BaseAgg struct {
@@ -68,6 +72,16 @@ func (agg *AvgAgg) Missing(val interface{}) *AvgAgg {
 	return agg
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *AvgAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // WeightedAvgAgg represents an aggregation of type "weighted_avg", as described
@@ -126,6 +140,16 @@ func (agg *WeightedAvgAgg) Map() map[string]interface{} {
 	}
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *WeightedAvgAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // CardinalityAgg represents an aggregation of type "cardinality", as described
@@ -166,6 +190,16 @@ func (agg *CardinalityAgg) Map() map[string]interface{} {
 	}
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *CardinalityAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // MaxAgg represents an aggregation of type "max", as described in:
@@ -189,6 +223,16 @@ func (agg *MaxAgg) Missing(val interface{}) *MaxAgg {
 	return agg
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *MaxAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // MinAgg represents an aggregation of type "min", as described in:
@@ -212,6 +256,16 @@ func (agg *MinAgg) Missing(val interface{}) *MinAgg {
 	return agg
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *MinAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // SumAgg represents an aggregation of type "sum", as described in:
@@ -235,6 +289,16 @@ func (agg *SumAgg) Missing(val interface{}) *SumAgg {
 	return agg
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *SumAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // ValueCountAgg represents an aggregation of type "value_count", as described
@@ -251,6 +315,16 @@ func ValueCount(name, field string) *ValueCountAgg {
 	}
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *ValueCountAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // PercentilesAgg represents an aggregation of type "percentiles", as described
@@ -326,6 +400,16 @@ func (agg *PercentilesAgg) Map() map[string]interface{} {
 	}
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *PercentilesAgg) Result(aggs AggregationResults) (*PercentilesResult, error) {
+	var res PercentilesResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 //----------------------------------------------------------------------------//
 
 // StatsAgg represents an aggregation of type "stats", as described in:
@@ -348,6 +432,16 @@ func (agg *StatsAgg) Missing(val interface{}) *StatsAgg {
 	return agg
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *StatsAgg) Result(aggs AggregationResults) (*StatsResult, error) {
+	var res StatsResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 // ---------------------------------------------------------------------------//
 
 // StringStatsAgg represents an aggregation of type "string_stats", as described
@@ -389,6 +483,16 @@ func (agg *StringStatsAgg) Map() map[string]interface{} {
 	}
 }
 
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *StringStatsAgg) Result(aggs AggregationResults) (*StringStatsResult, error) {
+	var res StringStatsResult
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 // ---------------------------------------------------------------------------//
 
 // TopHitsAgg represents an aggregation of type "top_hits", as described
@@ -466,3 +570,22 @@ func (agg *TopHitsAgg) Map() map[string]interface{} {
 		"top_hits": innerMap,
 	}
 }
+
+// Result decodes the top matching hits of the aggregation from the provided
+// aggregation results. The documents are left undecoded; use DecodeTopHits to
+// decode them into a specific type.
+func (agg *TopHitsAgg) Result(aggs AggregationResults) (*Hits[json.RawMessage], error) {
+	return DecodeTopHits[json.RawMessage](agg, aggs)
+}
+
+// DecodeTopHits decodes the top matching hits of a "top_hits" aggregation from
+// the provided aggregation results, with documents of type T.
+func DecodeTopHits[T any](agg *TopHitsAgg, aggs AggregationResults) (*Hits[T], error) {
+	var res struct {
+		Hits Hits[T] `json:"hits"`
+	}
+	if err := aggs.decode(agg.Name(), &res); err != nil {
+		return nil, err
+	}
+	return &res.Hits, nil
+}
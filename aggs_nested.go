@@ -55,3 +55,28 @@ func (agg *NestedAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *NestedAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *NestedAggregation) validate(path string, errs *ValidationErrors) {
+	if agg.path == "" {
+		errs.add(joinPath(path, "nested"), "path is required")
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. The results of sub-aggregations are available through the Aggs
+// field of the returned bucket.
+func (agg *NestedAggregation) Result(aggs AggregationResults) (*Bucket, error) {
+	var res Bucket
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,868 @@
+package osquery
+
+import "github.com/fatih/structs"
+
+// GapPolicy is an enumeration type representing supported values for a
+// pipeline aggregation's "gap_policy" parameter, which determines how buckets
+// with missing values are handled.
+type GapPolicy string
+
+const (
+	// GapPolicySkip skips buckets with missing values.
+	GapPolicySkip GapPolicy = "skip"
+
+	// GapPolicyInsertZeros replaces missing values with zero.
+	GapPolicyInsertZeros GapPolicy = "insert_zeros"
+)
+
+// scriptMap returns the inner "script" object of a ScriptField, or nil if the
+// script is nil.
+func scriptMap(script *ScriptField) map[string]interface{} {
+	if script == nil {
+		return nil
+	}
+	m, _ := script.Map()["script"].(map[string]interface{})
+	return m
+}
+
+//----------------------------------------------------------------------------//
+
+// BucketScriptAggregation represents a pipeline aggregation of type
+// "bucket_script", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#bucket_script-bucket_selector
+type BucketScriptAggregation struct {
+	name        string
+	bucketsPath map[string]string
+	script      *ScriptField
+	gapPolicy   GapPolicy
+	format      string
+}
+
+// BucketScriptAgg creates a new pipeline aggregation of type "bucket_script".
+// The buckets path maps script variable names to the paths of the metrics they
+// refer to, and the script computes a new value for every bucket of the
+// parent aggregation.
+func BucketScriptAgg(name string, bucketsPath map[string]string, script *ScriptField) *BucketScriptAggregation {
+	return &BucketScriptAggregation{
+		name:        name,
+		bucketsPath: bucketsPath,
+		script:      script,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *BucketScriptAggregation) Name() string {
+	return agg.name
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *BucketScriptAggregation) GapPolicy(policy GapPolicy) *BucketScriptAggregation {
+	agg.gapPolicy = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of each bucket.
+func (agg *BucketScriptAggregation) Format(format string) *BucketScriptAggregation {
+	agg.format = format
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *BucketScriptAggregation) Map() map[string]interface{} {
+	innerMap := map[string]interface{}{
+		"buckets_path": agg.bucketsPath,
+	}
+	if agg.script != nil {
+		innerMap["script"] = scriptMap(agg.script)
+	}
+	if agg.gapPolicy != "" {
+		innerMap["gap_policy"] = agg.gapPolicy
+	}
+	if agg.format != "" {
+		innerMap["format"] = agg.format
+	}
+
+	return map[string]interface{}{
+		"bucket_script": innerMap,
+	}
+}
+
+// Validate checks the aggregation for problems that would make OpenSearch
+// reject it. It returns a ValidationErrors value listing all the problems
+// found, or nil.
+func (agg *BucketScriptAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *BucketScriptAggregation) validate(path string, errs *ValidationErrors) {
+	validateScript(agg.script, joinPath(path, "bucket_script"), errs)
+}
+
+// Result decodes the value computed for a bucket from the bucket's aggregation
+// results.
+func (agg *BucketScriptAggregation) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// BucketSelectorAggregation represents a pipeline aggregation of type
+// "bucket_selector", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#bucket_script-bucket_selector
+type BucketSelectorAggregation struct {
+	name        string
+	bucketsPath map[string]string
+	script      *ScriptField
+	gapPolicy   GapPolicy
+}
+
+// BucketSelectorAgg creates a new pipeline aggregation of type
+// "bucket_selector". Buckets of the parent aggregation for which the script
+// returns false are removed from the response.
+func BucketSelectorAgg(name string, bucketsPath map[string]string, script *ScriptField) *BucketSelectorAggregation {
+	return &BucketSelectorAggregation{
+		name:        name,
+		bucketsPath: bucketsPath,
+		script:      script,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *BucketSelectorAggregation) Name() string {
+	return agg.name
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *BucketSelectorAggregation) GapPolicy(policy GapPolicy) *BucketSelectorAggregation {
+	agg.gapPolicy = policy
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *BucketSelectorAggregation) Map() map[string]interface{} {
+	innerMap := map[string]interface{}{
+		"buckets_path": agg.bucketsPath,
+	}
+	if agg.script != nil {
+		innerMap["script"] = scriptMap(agg.script)
+	}
+	if agg.gapPolicy != "" {
+		innerMap["gap_policy"] = agg.gapPolicy
+	}
+
+	return map[string]interface{}{
+		"bucket_selector": innerMap,
+	}
+}
+
+// Validate checks the aggregation for problems that would make OpenSearch
+// reject it. It returns a ValidationErrors value listing all the problems
+// found, or nil.
+func (agg *BucketSelectorAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *BucketSelectorAggregation) validate(path string, errs *ValidationErrors) {
+	validateScript(agg.script, joinPath(path, "bucket_selector"), errs)
+}
+
+//----------------------------------------------------------------------------//
+
+// BucketSortAggregation represents a pipeline aggregation of type
+// "bucket_sort", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#bucket_sort
+type BucketSortAggregation struct {
+	name      string
+	sort      []SortOption
+	from      *uint64
+	size      *uint64
+	gapPolicy GapPolicy
+}
+
+// BucketSortAgg creates a new pipeline aggregation of type "bucket_sort",
+// which sorts and/or truncates the buckets of its parent aggregation.
+func BucketSortAgg(name string) *BucketSortAggregation {
+	return &BucketSortAggregation{
+		name: name,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *BucketSortAggregation) Name() string {
+	return agg.name
+}
+
+// Sort appends one or more sort options. Fields refer to buckets paths, such as
+// "_key", "_count" or the name of a sibling metric aggregation.
+func (agg *BucketSortAggregation) Sort(opts ...SortOption) *BucketSortAggregation {
+	agg.sort = append(agg.sort, opts...)
+	return agg
+}
+
+// From sets the number of buckets to skip.
+func (agg *BucketSortAggregation) From(offset uint64) *BucketSortAggregation {
+	agg.from = &offset
+	return agg
+}
+
+// Size sets the number of buckets to return.
+func (agg *BucketSortAggregation) Size(size uint64) *BucketSortAggregation {
+	agg.size = &size
+	return agg
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *BucketSortAggregation) GapPolicy(policy GapPolicy) *BucketSortAggregation {
+	agg.gapPolicy = policy
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *BucketSortAggregation) Map() map[string]interface{} {
+	innerMap := make(map[string]interface{})
+	if len(agg.sort) > 0 {
+		sortSlice := make([]map[string]interface{}, 0, len(agg.sort))
+		for _, opt := range agg.sort {
+			sortSlice = append(sortSlice, opt.Map())
+		}
+		innerMap["sort"] = sortSlice
+	}
+	if agg.from != nil {
+		innerMap["from"] = *agg.from
+	}
+	if agg.size != nil {
+		innerMap["size"] = *agg.size
+	}
+	if agg.gapPolicy != "" {
+		innerMap["gap_policy"] = agg.gapPolicy
+	}
+
+	return map[string]interface{}{
+		"bucket_sort": innerMap,
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// BasePipelineAgg contains several fields that are common for the parent and
+// sibling pipeline aggregation types that operate on a single buckets path.
+type BasePipelineAgg struct {
+	name                   string
+	apiName                string
+	*BasePipelineAggParams `structs:",flatten"`
+}
+
+// BasePipelineAggParams contains fields that are common to most pipeline
+// aggregation types.
+type BasePipelineAggParams struct {
+	// BucketsPath is the path to the metric the aggregation operates on.
+	BucketsPath string `structs:"buckets_path"`
+	// Gap is the policy to apply when a bucket has a missing value.
+	Gap GapPolicy `structs:"gap_policy,omitempty"`
+	// Fmt is the format of the "value_as_string" of the output.
+	Fmt string `structs:"format,omitempty"`
+}
+
+func newBasePipelineAgg(apiName, name, bucketsPath string) *BasePipelineAgg {
+	return &BasePipelineAgg{
+		name:    name,
+		apiName: apiName,
+		BasePipelineAggParams: &BasePipelineAggParams{
+			BucketsPath: bucketsPath,
+		},
+	}
+}
+
+// Name returns the name of the aggregation, allowing implementation of the
+// Aggregation interface.
+func (agg *BasePipelineAgg) Name() string {
+	return agg.name
+}
+
+// Map returns a map representation of the aggregation, implementing the
+// Mappable interface.
+func (agg *BasePipelineAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg.BasePipelineAggParams),
+	}
+}
+
+// Result decodes the value computed by the aggregation from the provided
+// aggregation results. For parent pipeline aggregations, these are the results
+// of a bucket of the parent aggregation.
+func (agg *BasePipelineAgg) Result(aggs AggregationResults) (*ValueResult, error) {
+	var res ValueResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// DerivativeAgg represents a parent pipeline aggregation of type "derivative",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#derivative
+type DerivativeAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Unt is the time unit used to normalize the derivative
+	Unt string `structs:"unit,omitempty"`
+}
+
+// Derivative creates a new aggregation of type "derivative" on the provided
+// buckets path. It must be nested under a histogram or date histogram.
+func Derivative(name, bucketsPath string) *DerivativeAgg {
+	return &DerivativeAgg{
+		BasePipelineAgg: newBasePipelineAgg("derivative", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *DerivativeAgg) GapPolicy(policy GapPolicy) *DerivativeAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *DerivativeAgg) Format(format string) *DerivativeAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Unit sets the time unit, such as "1d", used to normalize the derivative.
+func (agg *DerivativeAgg) Unit(unit string) *DerivativeAgg {
+	agg.Unt = unit
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *DerivativeAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// CumulativeSumAgg represents a parent pipeline aggregation of type
+// "cumulative_sum", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#cumulative_sum
+type CumulativeSumAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// CumulativeSum creates a new aggregation of type "cumulative_sum" on the
+// provided buckets path. It must be nested under a histogram or date
+// histogram.
+func CumulativeSum(name, bucketsPath string) *CumulativeSumAgg {
+	return &CumulativeSumAgg{
+		BasePipelineAgg: newBasePipelineAgg("cumulative_sum", name, bucketsPath),
+	}
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *CumulativeSumAgg) Format(format string) *CumulativeSumAgg {
+	agg.Fmt = format
+	return agg
+}
+
+//----------------------------------------------------------------------------//
+
+// MovingAvgAgg represents a parent pipeline aggregation of type "moving_avg",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#moving_avg
+type MovingAvgAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Mdl is the moving average weighting model
+	Mdl string `structs:"model,omitempty"`
+
+	// Wndw is the size of the window to slide across the buckets
+	Wndw uint64 `structs:"window,omitempty"`
+
+	// Pred is the number of predictions to append to the series
+	Pred uint64 `structs:"predict,omitempty"`
+
+	// Minim sets whether the model's parameters should be minimized
+	Minim *bool `structs:"minimize,omitempty"`
+
+	// Sett includes the settings of the model
+	Sett map[string]interface{} `structs:"settings,omitempty"`
+}
+
+// MovingAvg creates a new aggregation of type "moving_avg" on the provided
+// buckets path. It must be nested under a histogram or date histogram.
+func MovingAvg(name, bucketsPath string) *MovingAvgAgg {
+	return &MovingAvgAgg{
+		BasePipelineAgg: newBasePipelineAgg("moving_avg", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *MovingAvgAgg) GapPolicy(policy GapPolicy) *MovingAvgAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *MovingAvgAgg) Format(format string) *MovingAvgAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Model sets the weighting model, one of "simple", "linear", "ewma", "holt" or
+// "holt_winters".
+func (agg *MovingAvgAgg) Model(model string) *MovingAvgAgg {
+	agg.Mdl = model
+	return agg
+}
+
+// Window sets the number of buckets to average over.
+func (agg *MovingAvgAgg) Window(size uint64) *MovingAvgAgg {
+	agg.Wndw = size
+	return agg
+}
+
+// Predict sets the number of predictions to append to the end of the series.
+func (agg *MovingAvgAgg) Predict(n uint64) *MovingAvgAgg {
+	agg.Pred = n
+	return agg
+}
+
+// Minimize sets whether the parameters of the model should be tuned
+// automatically.
+func (agg *MovingAvgAgg) Minimize(b bool) *MovingAvgAgg {
+	agg.Minim = &b
+	return agg
+}
+
+// Settings sets model-specific settings, such as "alpha" or "beta".
+func (agg *MovingAvgAgg) Settings(settings map[string]interface{}) *MovingAvgAgg {
+	agg.Sett = settings
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *MovingAvgAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// MovingFnAgg represents a parent pipeline aggregation of type "moving_fn", as
+// described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#moving_fn
+type MovingFnAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Scrpt is the script executed on each window of values
+	Scrpt string `structs:"script"`
+
+	// Wndw is the size of the window to slide across the buckets
+	Wndw uint64 `structs:"window"`
+
+	// Shft shifts the position of the window
+	Shft *int64 `structs:"shift,omitempty"`
+}
+
+// MovingFn creates a new aggregation of type "moving_fn" on the provided
+// buckets path, executing the provided script on windows of the provided size,
+// such as "MovingFunctions.unweightedAvg(values)". It must be nested under a
+// histogram or date histogram.
+func MovingFn(name, bucketsPath, script string, window uint64) *MovingFnAgg {
+	return &MovingFnAgg{
+		BasePipelineAgg: newBasePipelineAgg("moving_fn", name, bucketsPath),
+		Scrpt:           script,
+		Wndw:            window,
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *MovingFnAgg) GapPolicy(policy GapPolicy) *MovingFnAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *MovingFnAgg) Format(format string) *MovingFnAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Shift shifts the position of the window. By default the window excludes the
+// current bucket; a shift of 1 includes it.
+func (agg *MovingFnAgg) Shift(shift int64) *MovingFnAgg {
+	agg.Shft = &shift
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *MovingFnAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// SerialDiffAgg represents a parent pipeline aggregation of type
+// "serial_diff", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#serial_diff
+type SerialDiffAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Lg is the number of buckets to look back
+	Lg uint64 `structs:"lag,omitempty"`
+}
+
+// SerialDiff creates a new aggregation of type "serial_diff" on the provided
+// buckets path. It must be nested under a histogram or date histogram.
+func SerialDiff(name, bucketsPath string) *SerialDiffAgg {
+	return &SerialDiffAgg{
+		BasePipelineAgg: newBasePipelineAgg("serial_diff", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *SerialDiffAgg) GapPolicy(policy GapPolicy) *SerialDiffAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *SerialDiffAgg) Format(format string) *SerialDiffAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Lag sets the number of buckets to look back when computing differences.
+func (agg *SerialDiffAgg) Lag(lag uint64) *SerialDiffAgg {
+	agg.Lg = lag
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *SerialDiffAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// AvgBucketAgg represents a sibling pipeline aggregation of type "avg_bucket",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#avg_bucket-sum_bucket-min_bucket-and-max_bucket
+type AvgBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// AvgBucket creates a new aggregation of type "avg_bucket" on the provided
+// buckets path, such as "sales_per_month>sales".
+func AvgBucket(name, bucketsPath string) *AvgBucketAgg {
+	return &AvgBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("avg_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *AvgBucketAgg) GapPolicy(policy GapPolicy) *AvgBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *AvgBucketAgg) Format(format string) *AvgBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+//----------------------------------------------------------------------------//
+
+// SumBucketAgg represents a sibling pipeline aggregation of type "sum_bucket",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#avg_bucket-sum_bucket-min_bucket-and-max_bucket
+type SumBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// SumBucket creates a new aggregation of type "sum_bucket" on the provided
+// buckets path.
+func SumBucket(name, bucketsPath string) *SumBucketAgg {
+	return &SumBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("sum_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *SumBucketAgg) GapPolicy(policy GapPolicy) *SumBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *SumBucketAgg) Format(format string) *SumBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+//----------------------------------------------------------------------------//
+
+// BucketValueResult is the result of a "max_bucket" or "min_bucket"
+// aggregation. Keys holds the keys of the buckets holding the value.
+type BucketValueResult struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string,omitempty"`
+	Keys          []string `json:"keys"`
+}
+
+// MaxBucketAgg represents a sibling pipeline aggregation of type "max_bucket",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#avg_bucket-sum_bucket-min_bucket-and-max_bucket
+type MaxBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// MaxBucket creates a new aggregation of type "max_bucket" on the provided
+// buckets path.
+func MaxBucket(name, bucketsPath string) *MaxBucketAgg {
+	return &MaxBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("max_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *MaxBucketAgg) GapPolicy(policy GapPolicy) *MaxBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *MaxBucketAgg) Format(format string) *MaxBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *MaxBucketAgg) Result(aggs AggregationResults) (*BucketValueResult, error) {
+	var res BucketValueResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// MinBucketAgg represents a sibling pipeline aggregation of type "min_bucket",
+// as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#avg_bucket-sum_bucket-min_bucket-and-max_bucket
+type MinBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// MinBucket creates a new aggregation of type "min_bucket" on the provided
+// buckets path.
+func MinBucket(name, bucketsPath string) *MinBucketAgg {
+	return &MinBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("min_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *MinBucketAgg) GapPolicy(policy GapPolicy) *MinBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "value_as_string" of the output.
+func (agg *MinBucketAgg) Format(format string) *MinBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *MinBucketAgg) Result(aggs AggregationResults) (*BucketValueResult, error) {
+	var res BucketValueResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// StatsBucketAgg represents a sibling pipeline aggregation of type
+// "stats_bucket", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#stats_bucket-extended_stats_bucket
+type StatsBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+}
+
+// StatsBucket creates a new aggregation of type "stats_bucket" on the provided
+// buckets path.
+func StatsBucket(name, bucketsPath string) *StatsBucketAgg {
+	return &StatsBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("stats_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *StatsBucketAgg) GapPolicy(policy GapPolicy) *StatsBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "_as_string" values of the output.
+func (agg *StatsBucketAgg) Format(format string) *StatsBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *StatsBucketAgg) Result(aggs AggregationResults) (*StatsResult, error) {
+	var res StatsResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// ExtendedStatsBucketAgg represents a sibling pipeline aggregation of type
+// "extended_stats_bucket", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#stats_bucket-extended_stats_bucket
+type ExtendedStatsBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Sgm is the number of standard deviations above/below the mean to display
+	Sgm *float64 `structs:"sigma,omitempty"`
+}
+
+// ExtendedStatsBucket creates a new aggregation of type
+// "extended_stats_bucket" on the provided buckets path.
+func ExtendedStatsBucket(name, bucketsPath string) *ExtendedStatsBucketAgg {
+	return &ExtendedStatsBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("extended_stats_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *ExtendedStatsBucketAgg) GapPolicy(policy GapPolicy) *ExtendedStatsBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "_as_string" values of the output.
+func (agg *ExtendedStatsBucketAgg) Format(format string) *ExtendedStatsBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Sigma sets the number of standard deviations above and below the mean used
+// to compute the standard deviation bounds.
+func (agg *ExtendedStatsBucketAgg) Sigma(sigma float64) *ExtendedStatsBucketAgg {
+	agg.Sgm = &sigma
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *ExtendedStatsBucketAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *ExtendedStatsBucketAgg) Result(aggs AggregationResults) (*ExtendedStatsResult, error) {
+	var res ExtendedStatsResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// PercentilesBucketAgg represents a sibling pipeline aggregation of type
+// "percentiles_bucket", as described in
+// https://opensearch.org/docs/latest/aggregations/pipeline-agg/#percentiles_bucket
+type PercentilesBucketAgg struct {
+	*BasePipelineAgg `structs:",flatten"`
+
+	// Prcnts is the aggregation's percentages
+	Prcnts []float32 `structs:"percents,omitempty"`
+
+	// Key denotes whether the aggregation is keyed or not
+	Key *bool `structs:"keyed,omitempty"`
+}
+
+// PercentilesBucket creates a new aggregation of type "percentiles_bucket" on
+// the provided buckets path.
+func PercentilesBucket(name, bucketsPath string) *PercentilesBucketAgg {
+	return &PercentilesBucketAgg{
+		BasePipelineAgg: newBasePipelineAgg("percentiles_bucket", name, bucketsPath),
+	}
+}
+
+// GapPolicy sets the policy to apply when a bucket has a missing value.
+func (agg *PercentilesBucketAgg) GapPolicy(policy GapPolicy) *PercentilesBucketAgg {
+	agg.Gap = policy
+	return agg
+}
+
+// Format sets the format of the "_as_string" values of the output.
+func (agg *PercentilesBucketAgg) Format(format string) *PercentilesBucketAgg {
+	agg.Fmt = format
+	return agg
+}
+
+// Percents sets the aggregation's percentages.
+func (agg *PercentilesBucketAgg) Percents(percents ...float32) *PercentilesBucketAgg {
+	agg.Prcnts = percents
+	return agg
+}
+
+// Keyed sets whether the aggregate is keyed or not.
+func (agg *PercentilesBucketAgg) Keyed(b bool) *PercentilesBucketAgg {
+	agg.Key = &b
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *PercentilesBucketAgg) Map() map[string]interface{} {
+	return map[string]interface{}{
+		agg.apiName: structs.Map(agg),
+	}
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results.
+func (agg *PercentilesBucketAgg) Result(aggs AggregationResults) (*PercentilesResult, error) {
+	var res PercentilesResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
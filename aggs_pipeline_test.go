@@ -0,0 +1,335 @@
+package osquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestBucketPipelineAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"bucket_script agg",
+			BucketScriptAgg(
+				"ratio",
+				map[string]string{"errors": "errors>_count", "total": "_count"},
+				Script("").Source("params.errors / params.total").Lang("painless"),
+			).GapPolicy(GapPolicyInsertZeros).Format("0.00"),
+			map[string]interface{}{
+				"bucket_script": map[string]interface{}{
+					"buckets_path": map[string]interface{}{
+						"errors": "errors>_count",
+						"total":  "_count",
+					},
+					"script": map[string]interface{}{
+						"source": "params.errors / params.total",
+						"lang":   "painless",
+					},
+					"gap_policy": "insert_zeros",
+					"format":     "0.00",
+				},
+			},
+		},
+		{
+			"bucket_selector and bucket_sort nested under terms",
+			TermsAgg("hosts", "host").Aggs(
+				Sum("bytes", "bytes"),
+				BucketSelectorAgg(
+					"large_hosts",
+					map[string]string{"bytes": "bytes"},
+					Script("").Source("params.bytes > 1000"),
+				).GapPolicy(GapPolicySkip),
+				BucketSortAgg("top_hosts").
+					Sort(FieldSort("bytes").Order(OrderDesc)).
+					From(0).
+					Size(3),
+			),
+			map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "host",
+				},
+				"aggs": map[string]interface{}{
+					"bytes": map[string]interface{}{
+						"sum": map[string]interface{}{
+							"field": "bytes",
+						},
+					},
+					"large_hosts": map[string]interface{}{
+						"bucket_selector": map[string]interface{}{
+							"buckets_path": map[string]interface{}{
+								"bytes": "bytes",
+							},
+							"script": map[string]interface{}{
+								"source": "params.bytes > 1000",
+							},
+							"gap_policy": "skip",
+						},
+					},
+					"top_hosts": map[string]interface{}{
+						"bucket_sort": map[string]interface{}{
+							"sort": []map[string]interface{}{
+								{"bytes": map[string]interface{}{"order": "desc"}},
+							},
+							"from": 0,
+							"size": 3,
+						},
+					},
+				},
+			},
+		},
+		{
+			"bucket_script nested under date_histogram",
+			DateHistogramAgg("per_day", "@timestamp").
+				CalendarInterval("day").
+				Aggs(
+					Sum("sales", "price"),
+					BucketScriptAgg(
+						"sales_in_k",
+						map[string]string{"sales": "sales"},
+						Script("").Source("params.sales / 1000"),
+					),
+				),
+			map[string]interface{}{
+				"date_histogram": map[string]interface{}{
+					"field":             "@timestamp",
+					"calendar_interval": "day",
+				},
+				"aggs": map[string]interface{}{
+					"sales": map[string]interface{}{
+						"sum": map[string]interface{}{
+							"field": "price",
+						},
+					},
+					"sales_in_k": map[string]interface{}{
+						"bucket_script": map[string]interface{}{
+							"buckets_path": map[string]interface{}{
+								"sales": "sales",
+							},
+							"script": map[string]interface{}{
+								"source": "params.sales / 1000",
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestParentPipelineAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"parent pipeline aggs nested under date_histogram",
+			DateHistogramAgg("per_month", "@timestamp").
+				CalendarInterval("month").
+				Aggs(
+					Sum("sales", "price"),
+					Derivative("sales_deriv", "sales").Unit("1d").GapPolicy(GapPolicySkip),
+					CumulativeSum("sales_total", "sales").Format("0.0"),
+					MovingAvg("sales_avg", "sales").
+						Model("holt").
+						Window(5).
+						Predict(2).
+						Minimize(true).
+						Settings(map[string]interface{}{"alpha": 0.5}),
+					MovingFn("sales_fn", "sales", "MovingFunctions.unweightedAvg(values)", 10).
+						Shift(1).
+						GapPolicy(GapPolicyInsertZeros),
+					SerialDiff("sales_diff", "sales").Lag(7),
+				),
+			map[string]interface{}{
+				"date_histogram": map[string]interface{}{
+					"field":             "@timestamp",
+					"calendar_interval": "month",
+				},
+				"aggs": map[string]interface{}{
+					"sales": map[string]interface{}{
+						"sum": map[string]interface{}{
+							"field": "price",
+						},
+					},
+					"sales_deriv": map[string]interface{}{
+						"derivative": map[string]interface{}{
+							"buckets_path": "sales",
+							"gap_policy":   "skip",
+							"unit":         "1d",
+						},
+					},
+					"sales_total": map[string]interface{}{
+						"cumulative_sum": map[string]interface{}{
+							"buckets_path": "sales",
+							"format":       "0.0",
+						},
+					},
+					"sales_avg": map[string]interface{}{
+						"moving_avg": map[string]interface{}{
+							"buckets_path": "sales",
+							"model":        "holt",
+							"window":       5,
+							"predict":      2,
+							"minimize":     true,
+							"settings": map[string]interface{}{
+								"alpha": 0.5,
+							},
+						},
+					},
+					"sales_fn": map[string]interface{}{
+						"moving_fn": map[string]interface{}{
+							"buckets_path": "sales",
+							"script":       "MovingFunctions.unweightedAvg(values)",
+							"window":       10,
+							"shift":        1,
+							"gap_policy":   "insert_zeros",
+						},
+					},
+					"sales_diff": map[string]interface{}{
+						"serial_diff": map[string]interface{}{
+							"buckets_path": "sales",
+							"lag":          7,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestSiblingPipelineAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"sibling pipeline aggs alongside a date_histogram",
+			Aggregate(
+				DateHistogramAgg("per_month", "@timestamp").
+					CalendarInterval("month").
+					Aggs(Sum("sales", "price")),
+				AvgBucket("avg_sales", "per_month>sales").GapPolicy(GapPolicySkip),
+				MaxBucket("max_sales", "per_month>sales"),
+				MinBucket("min_sales", "per_month>sales").Format("0"),
+				SumBucket("sum_sales", "per_month>sales"),
+				StatsBucket("stats_sales", "per_month>sales"),
+				ExtendedStatsBucket("ext_sales", "per_month>sales").Sigma(3),
+				PercentilesBucket("pct_sales", "per_month>sales").Percents(25, 50).Keyed(false),
+			),
+			map[string]interface{}{
+				"aggs": map[string]interface{}{
+					"per_month": map[string]interface{}{
+						"date_histogram": map[string]interface{}{
+							"field":             "@timestamp",
+							"calendar_interval": "month",
+						},
+						"aggs": map[string]interface{}{
+							"sales": map[string]interface{}{
+								"sum": map[string]interface{}{
+									"field": "price",
+								},
+							},
+						},
+					},
+					"avg_sales": map[string]interface{}{
+						"avg_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+							"gap_policy":   "skip",
+						},
+					},
+					"max_sales": map[string]interface{}{
+						"max_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+						},
+					},
+					"min_sales": map[string]interface{}{
+						"min_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+							"format":       "0",
+						},
+					},
+					"sum_sales": map[string]interface{}{
+						"sum_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+						},
+					},
+					"stats_sales": map[string]interface{}{
+						"stats_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+						},
+					},
+					"ext_sales": map[string]interface{}{
+						"extended_stats_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+							"sigma":        3,
+						},
+					},
+					"pct_sales": map[string]interface{}{
+						"percentiles_bucket": map[string]interface{}{
+							"buckets_path": "per_month>sales",
+							"percents":     []float32{25, 50},
+							"keyed":        false,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestPipelineAggResults(t *testing.T) {
+	avgSales := AvgBucket("avg_sales", "per_month>sales")
+	maxSales := MaxBucket("max_sales", "per_month>sales")
+	extSales := ExtendedStatsBucket("ext_sales", "per_month>sales")
+
+	aggs, err := ParseAggregations(json.RawMessage(`{
+		"avg_sales": {"value": 328.33},
+		"max_sales": {"value": 550, "keys": ["2024-03-01"]},
+		"ext_sales": {
+			"count": 3, "min": 60, "max": 550, "avg": 328.33, "sum": 985,
+			"sum_of_squares": 446725, "variance": 41105.55, "std_deviation": 202.74,
+			"std_deviation_bounds": {"upper": 733.82, "lower": -77.15}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	avg, err := avgSales.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 328.33, *avg.Value)
+
+	max, err := maxSales.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 550.0, *max.Value)
+	assert.DeepEqual(t, []string{"2024-03-01"}, max.Keys)
+
+	ext, err := extSales.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), ext.Count)
+	assert.Equal(t, 202.74, *ext.StdDeviation)
+	assert.Equal(t, 733.82, *ext.StdDeviationBounds.Upper)
+}
+
+func TestPipelineAggsWithoutScript(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"bucket_script without script",
+			BucketScriptAgg("ratio", map[string]string{"a": "a"}, nil),
+			map[string]interface{}{
+				"bucket_script": map[string]interface{}{
+					"buckets_path": map[string]string{"a": "a"},
+				},
+			},
+		},
+		{
+			"bucket_selector without script",
+			BucketSelectorAgg("big", map[string]string{"a": "a"}, nil),
+			map[string]interface{}{
+				"bucket_selector": map[string]interface{}{
+					"buckets_path": map[string]string{"a": "a"},
+				},
+			},
+		},
+		{
+			"script_score function without script",
+			ScriptScoreFunction(nil),
+			map[string]interface{}{
+				"script_score": map[string]interface{}{},
+			},
+		},
+	})
+}
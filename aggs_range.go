@@ -0,0 +1,272 @@
+package osquery
+
+import "encoding/json"
+
+// RangeAggregation represents an aggregation of type "range", "date_range" or
+// "ip_range", as described in:
+// - https://opensearch.org/docs/latest/aggregations/bucket/range/
+// - https://opensearch.org/docs/latest/aggregations/bucket/date-range/
+// - https://opensearch.org/docs/latest/aggregations/bucket/ip-range/
+// While all three share the same general structure, they don't support all
+// the same options. The library does not attempt to verify provided options
+// are supported.
+type RangeAggregation struct {
+	name     string
+	apiName  string
+	field    string
+	ranges   []aggRange
+	format   string
+	timeZone string
+	keyed    *bool
+	missing  interface{}
+	aggs     []Aggregation
+}
+
+type aggRange struct {
+	key  string
+	from interface{}
+	to   interface{}
+	mask string
+}
+
+// RangeAgg creates a new aggregation of type "range" on the provided numeric
+// field. The method name includes the "Agg" suffix to prevent conflict with the
+// "range" query.
+func RangeAgg(name, field string) *RangeAggregation {
+	return newRangeAgg("range", name, field)
+}
+
+// DateRangeAgg creates a new aggregation of type "date_range" on the provided
+// date field. Range boundaries may be dates or date math expressions such as
+// "now-10d/d".
+func DateRangeAgg(name, field string) *RangeAggregation {
+	return newRangeAgg("date_range", name, field)
+}
+
+// IPRangeAgg creates a new aggregation of type "ip_range" on the provided IP
+// field. Ranges can be provided as IP boundaries or as CIDR masks.
+func IPRangeAgg(name, field string) *RangeAggregation {
+	return newRangeAgg("ip_range", name, field)
+}
+
+func newRangeAgg(apiName, name, field string) *RangeAggregation {
+	return &RangeAggregation{
+		name:    name,
+		apiName: apiName,
+		field:   field,
+	}
+}
+
+// Name returns the name of the aggregation.
+func (agg *RangeAggregation) Name() string {
+	return agg.name
+}
+
+// Range adds a range to the aggregation. The "from" value is inclusive and the
+// "to" value is exclusive; either can be nil to leave that side of the range
+// unbounded.
+func (agg *RangeAggregation) Range(from, to interface{}) *RangeAggregation {
+	return agg.KeyedRange("", from, to)
+}
+
+// KeyedRange adds a range to the aggregation, with a custom key for its bucket.
+func (agg *RangeAggregation) KeyedRange(key string, from, to interface{}) *RangeAggregation {
+	agg.ranges = append(agg.ranges, aggRange{
+		key:  key,
+		from: from,
+		to:   to,
+	})
+	return agg
+}
+
+// Mask adds a range defined by a CIDR mask, such as "10.0.0.0/25". Masks are
+// only supported by "ip_range" aggregations.
+func (agg *RangeAggregation) Mask(mask string) *RangeAggregation {
+	return agg.KeyedMask("", mask)
+}
+
+// KeyedMask adds a range defined by a CIDR mask, with a custom key for its
+// bucket.
+func (agg *RangeAggregation) KeyedMask(key, mask string) *RangeAggregation {
+	agg.ranges = append(agg.ranges, aggRange{
+		key:  key,
+		mask: mask,
+	})
+	return agg
+}
+
+// Format sets the format of the "from_as_string" and "to_as_string" values of
+// each bucket, and the format used to parse date boundaries.
+func (agg *RangeAggregation) Format(format string) *RangeAggregation {
+	agg.format = format
+	return agg
+}
+
+// TimeZone sets the time zone used to parse date boundaries and date math.
+// Only supported by "date_range" aggregations.
+func (agg *RangeAggregation) TimeZone(zone string) *RangeAggregation {
+	agg.timeZone = zone
+	return agg
+}
+
+// Keyed sets whether buckets should be returned as a map keyed by the bucket
+// key rather than as a list.
+func (agg *RangeAggregation) Keyed(b bool) *RangeAggregation {
+	agg.keyed = &b
+	return agg
+}
+
+// Missing sets the value to use for documents missing a value for the field.
+func (agg *RangeAggregation) Missing(val interface{}) *RangeAggregation {
+	agg.missing = val
+	return agg
+}
+
+// Aggs sets sub-aggregations for the aggregation.
+func (agg *RangeAggregation) Aggs(aggs ...Aggregation) *RangeAggregation {
+	agg.aggs = aggs
+	return agg
+}
+
+// Map returns a map representation of the aggregation, thus implementing the
+// Mappable interface.
+func (agg *RangeAggregation) Map() map[string]interface{} {
+	ranges := make([]map[string]interface{}, len(agg.ranges))
+	for i, r := range agg.ranges {
+		m := make(map[string]interface{})
+		if r.key != "" {
+			m["key"] = r.key
+		}
+		if r.mask != "" {
+			m["mask"] = r.mask
+		}
+		if r.from != nil {
+			m["from"] = r.from
+		}
+		if r.to != nil {
+			m["to"] = r.to
+		}
+		ranges[i] = m
+	}
+
+	innerMap := map[string]interface{}{
+		"field":  agg.field,
+		"ranges": ranges,
+	}
+
+	if agg.format != "" {
+		innerMap["format"] = agg.format
+	}
+	if agg.timeZone != "" {
+		innerMap["time_zone"] = agg.timeZone
+	}
+	if agg.keyed != nil {
+		innerMap["keyed"] = *agg.keyed
+	}
+	if agg.missing != nil {
+		innerMap["missing"] = agg.missing
+	}
+
+	outerMap := map[string]interface{}{
+		agg.apiName: innerMap,
+	}
+	if len(agg.aggs) > 0 {
+		subAggs := make(map[string]map[string]interface{})
+		for _, sub := range agg.aggs {
+			subAggs[sub.Name()] = sub.Map()
+		}
+		outerMap["aggs"] = subAggs
+	}
+
+	return outerMap
+}
+
+// Validate checks the aggregation and its sub-aggregations for problems that
+// would make OpenSearch reject it. It returns a ValidationErrors value listing
+// all the problems found, or nil.
+func (agg *RangeAggregation) Validate() error {
+	return validate(agg, agg.name)
+}
+
+func (agg *RangeAggregation) validate(path string, errs *ValidationErrors) {
+	if len(agg.ranges) == 0 {
+		errs.add(joinPath(path, agg.apiName), "at least one range is required")
+	}
+	validateAggs(agg.aggs, joinPath(path, "aggs"), errs)
+}
+
+// Result decodes the result of the aggregation from the provided aggregation
+// results. Buckets are returned in the order of the ranges, whether or not the
+// aggregation is keyed.
+func (agg *RangeAggregation) Result(aggs AggregationResults) (*RangeResult, error) {
+	var res RangeResult
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// RangeResult is the result of a "range", "date_range" or "ip_range"
+// aggregation.
+type RangeResult struct {
+	Buckets []RangeBucket
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (res *RangeResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Buckets json.RawMessage `json:"buckets"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	buckets, err := decodeBuckets(raw.Buckets, func(b *RangeBucket, key string) {
+		if b.Key == "" {
+			b.Key = key
+		}
+	})
+	if err != nil {
+		return err
+	}
+	res.Buckets = buckets
+	return nil
+}
+
+// RangeBucket is a single bucket of a range aggregation. From and To are nil
+// for unbounded sides of the range; they are numbers for "range" and
+// "date_range" aggregations, and strings for "ip_range" aggregations.
+type RangeBucket struct {
+	Key          string
+	From         interface{}
+	FromAsString string
+	To           interface{}
+	ToAsString   string
+	DocCount     int64
+	Aggs         AggregationResults
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *RangeBucket) UnmarshalJSON(data []byte) error {
+	fields, aggs, err := splitBucket(data)
+	if err != nil {
+		return err
+	}
+
+	b.Aggs = aggs
+	for name, out := range map[string]interface{}{
+		"key":            &b.Key,
+		"from":           &b.From,
+		"from_as_string": &b.FromAsString,
+		"to":             &b.To,
+		"to_as_string":   &b.ToAsString,
+		"doc_count":      &b.DocCount,
+	} {
+		if err := decodeField(fields, name, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
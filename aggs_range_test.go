@@ -0,0 +1,118 @@
+package osquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestRangeAggs(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"range agg",
+			RangeAgg("latency", "took").
+				Range(nil, 100).
+				KeyedRange("medium", 100, 500).
+				Range(500, nil).
+				Missing(0).
+				Aggs(Avg("avg_size", "size")),
+			map[string]interface{}{
+				"range": map[string]interface{}{
+					"field": "took",
+					"ranges": []map[string]interface{}{
+						{"to": 100},
+						{"key": "medium", "from": 100, "to": 500},
+						{"from": 500},
+					},
+					"missing": 0,
+				},
+				"aggs": map[string]interface{}{
+					"avg_size": map[string]interface{}{
+						"avg": map[string]interface{}{
+							"field": "size",
+						},
+					},
+				},
+			},
+		},
+		{
+			"date_range agg",
+			DateRangeAgg("recent", "@timestamp").
+				KeyedRange("last_week", "now-7d/d", "now").
+				Range(nil, "now-7d/d").
+				Format("yyyy-MM-dd").
+				TimeZone("UTC").
+				Keyed(true),
+			map[string]interface{}{
+				"date_range": map[string]interface{}{
+					"field": "@timestamp",
+					"ranges": []map[string]interface{}{
+						{"key": "last_week", "from": "now-7d/d", "to": "now"},
+						{"to": "now-7d/d"},
+					},
+					"format":    "yyyy-MM-dd",
+					"time_zone": "UTC",
+					"keyed":     true,
+				},
+			},
+		},
+		{
+			"ip_range agg",
+			IPRangeAgg("subnets", "source.ip").
+				Range("10.0.0.5", "10.0.0.10").
+				Mask("10.0.0.0/25").
+				KeyedMask("upper", "10.0.0.128/25"),
+			map[string]interface{}{
+				"ip_range": map[string]interface{}{
+					"field": "source.ip",
+					"ranges": []map[string]interface{}{
+						{"from": "10.0.0.5", "to": "10.0.0.10"},
+						{"mask": "10.0.0.0/25"},
+						{"key": "upper", "mask": "10.0.0.128/25"},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestRangeResults(t *testing.T) {
+	latency := RangeAgg("latency", "took").Range(nil, 100).Range(100, nil)
+	subnets := IPRangeAgg("subnets", "source.ip").Mask("10.0.0.0/25").Keyed(true)
+	avgSize := Avg("avg_size", "size")
+	latency.Aggs(avgSize)
+
+	aggs, err := ParseAggregations(json.RawMessage(`{
+		"latency": {
+			"buckets": [
+				{"key": "*-100.0", "to": 100.0, "doc_count": 4, "avg_size": {"value": 12}},
+				{"key": "100.0-*", "from": 100.0, "doc_count": 1, "avg_size": {"value": 40}}
+			]
+		},
+		"subnets": {
+			"buckets": {
+				"10.0.0.0/25": {"from": "10.0.0.0", "to": "10.0.0.128", "doc_count": 7}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	ranges, err := latency.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ranges.Buckets))
+	assert.Equal(t, "*-100.0", ranges.Buckets[0].Key)
+	assert.Nil(t, ranges.Buckets[0].From)
+	assert.Equal(t, 100.0, ranges.Buckets[0].To)
+	assert.Equal(t, int64(4), ranges.Buckets[0].DocCount)
+
+	size, err := avgSize.Result(ranges.Buckets[1].Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 40.0, *size.Value)
+
+	ips, err := subnets.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.0/25", ips.Buckets[0].Key)
+	assert.Equal(t, "10.0.0.128", ips.Buckets[0].To)
+	assert.Equal(t, int64(7), ips.Buckets[0].DocCount)
+}
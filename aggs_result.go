@@ -0,0 +1,293 @@
+package osquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// AggregationResults holds the undecoded results of one level of an
+// aggregation tree, keyed by aggregation name. Every aggregation type provides
+// a Result method that decodes its own entry, so results can be navigated using
+// the same builders (and names) that were passed to Aggs.
+type AggregationResults map[string]json.RawMessage
+
+// ParseAggregations parses the "aggregations" section of a search response,
+// such as opensearchapi.SearchResp.Aggregations.
+func ParseAggregations(raw json.RawMessage) (AggregationResults, error) {
+	results := make(AggregationResults)
+	if len(raw) == 0 || string(raw) == "null" {
+		return results, nil
+	}
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse aggregations: %w", err)
+	}
+	return results, nil
+}
+
+// decode finds the result of the aggregation with the provided name and
+// decodes it into out.
+func (results AggregationResults) decode(name string, out interface{}) error {
+	raw, ok := results[name]
+	if !ok {
+		return fmt.Errorf("aggregation %q not found in results", name)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode aggregation %q: %w", name, err)
+	}
+	return nil
+}
+
+//----------------------------------------------------------------------------//
+
+// ValueResult is the result of a single-value metric aggregation, such as
+// "avg", "max", "min", "sum", "value_count", "cardinality" or "weighted_avg".
+// Value is nil when no document had a value for the aggregated field.
+type ValueResult struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string,omitempty"`
+}
+
+// StatsResult is the result of a "stats" aggregation.
+type StatsResult struct {
+	Count int64    `json:"count"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Avg   *float64 `json:"avg"`
+	Sum   float64  `json:"sum"`
+}
+
+// ExtendedStatsResult is the result of an "extended_stats_bucket" aggregation.
+type ExtendedStatsResult struct {
+	StatsResult
+
+	SumOfSquares       *float64 `json:"sum_of_squares"`
+	Variance           *float64 `json:"variance"`
+	StdDeviation       *float64 `json:"std_deviation"`
+	StdDeviationBounds struct {
+		Upper *float64 `json:"upper"`
+		Lower *float64 `json:"lower"`
+	} `json:"std_deviation_bounds"`
+}
+
+// StringStatsResult is the result of a "string_stats" aggregation.
+type StringStatsResult struct {
+	Count        int64              `json:"count"`
+	MinLength    *float64           `json:"min_length"`
+	MaxLength    *float64           `json:"max_length"`
+	AvgLength    *float64           `json:"avg_length"`
+	Entropy      float64            `json:"entropy"`
+	Distribution map[string]float64 `json:"distribution,omitempty"`
+}
+
+// PercentilesResult is the result of a "percentiles" aggregation. Values maps
+// each requested percentage to its computed percentile. Both the keyed and the
+// non-keyed response formats are supported. Percentiles OpenSearch could not
+// compute are omitted.
+type PercentilesResult struct {
+	Values map[float64]float64
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (res *PercentilesResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Values json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	res.Values = make(map[float64]float64)
+	if len(raw.Values) == 0 {
+		return nil
+	}
+
+	// non-keyed format: [{"key": 50.0, "value": 12.3}, ...]
+	if raw.Values[0] == '[' {
+		var list []struct {
+			Key   float64  `json:"key"`
+			Value *float64 `json:"value"`
+		}
+		if err := json.Unmarshal(raw.Values, &list); err != nil {
+			return err
+		}
+		for _, v := range list {
+			if v.Value != nil {
+				res.Values[v.Key] = *v.Value
+			}
+		}
+		return nil
+	}
+
+	// keyed format: {"50.0": 12.3, ...}
+	var keyed map[string]*float64
+	if err := json.Unmarshal(raw.Values, &keyed); err != nil {
+		return err
+	}
+	for k, v := range keyed {
+		if v == nil {
+			continue
+		}
+		percent, err := strconv.ParseFloat(k, 64)
+		if err != nil {
+			// skip "_as_string" entries returned when a format is set
+			continue
+		}
+		res.Values[percent] = *v
+	}
+	return nil
+}
+
+//----------------------------------------------------------------------------//
+
+// Bucket is a single bucket returned by a bucket aggregation. Single-bucket
+// aggregations such as "filter" and "nested" also decode into a Bucket, with
+// an empty key. Results of sub-aggregations are available through Aggs, keyed
+// by the names used when building them.
+type Bucket struct {
+	Key         interface{}
+	KeyAsString string
+	DocCount    int64
+
+	// DocCountErrorUpperBound is only set for buckets of "terms" aggregations
+	// requested with ShowTermDocCountError.
+	DocCountErrorUpperBound *int64
+
+	Aggs AggregationResults
+}
+
+// bucketFields are the keys of a bucket object that are not sub-aggregations.
+var bucketFields = map[string]bool{
+	"key":                         true,
+	"key_as_string":               true,
+	"doc_count":                   true,
+	"doc_count_error_upper_bound": true,
+	"from":                        true,
+	"from_as_string":              true,
+	"to":                          true,
+	"to_as_string":                true,
+	"mask":                        true,
+}
+
+// splitBucket separates the fields of a bucket object from the results of its
+// sub-aggregations.
+func splitBucket(data []byte) (fields map[string]json.RawMessage, aggs AggregationResults, err error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, nil, err
+	}
+
+	fields = make(map[string]json.RawMessage)
+	aggs = make(AggregationResults)
+	for k, v := range all {
+		if bucketFields[k] {
+			fields[k] = v
+		} else if len(v) > 0 && v[0] == '{' {
+			aggs[k] = v
+		}
+	}
+	return fields, aggs, nil
+}
+
+// decodeField decodes the field with the provided name, if it exists.
+func decodeField(fields map[string]json.RawMessage, name string, out interface{}) error {
+	raw, ok := fields[name]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	fields, aggs, err := splitBucket(data)
+	if err != nil {
+		return err
+	}
+
+	b.Aggs = aggs
+	if err := decodeField(fields, "key", &b.Key); err != nil {
+		return err
+	}
+	if err := decodeField(fields, "key_as_string", &b.KeyAsString); err != nil {
+		return err
+	}
+	if err := decodeField(fields, "doc_count", &b.DocCount); err != nil {
+		return err
+	}
+	return decodeField(fields, "doc_count_error_upper_bound", &b.DocCountErrorUpperBound)
+}
+
+// TermsResult is the result of a "terms" aggregation.
+type TermsResult struct {
+	DocCountErrorUpperBound int64    `json:"doc_count_error_upper_bound"`
+	SumOtherDocCount        int64    `json:"sum_other_doc_count"`
+	Buckets                 []Bucket `json:"buckets"`
+}
+
+// decodeBuckets decodes the "buckets" of a multi-bucket aggregation. Keyed
+// aggregations return their buckets as an object rather than a list; these are
+// decoded in the order OpenSearch returned them, and setKey is called for each
+// bucket with its object key.
+func decodeBuckets[B any](raw json.RawMessage, setKey func(b *B, key string)) ([]B, error) {
+	keys, values, err := orderedObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]B, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal(v, &buckets[i]); err != nil {
+			return nil, err
+		}
+		if keys != nil {
+			setKey(&buckets[i], keys[i])
+		}
+	}
+	return buckets, nil
+}
+
+// setBucketKey sets the key of a keyed bucket that doesn't include its own.
+func setBucketKey(b *Bucket, key string) {
+	if b.Key == nil {
+		b.Key = key
+	}
+}
+
+// orderedObject splits a JSON list or object into its elements, preserving
+// their order. For objects, the keys of the elements are returned as well; for
+// lists, keys is nil.
+func orderedObject(raw json.RawMessage) (keys []string, values []json.RawMessage, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, nil
+	}
+	if raw[0] == '[' {
+		err = json.Unmarshal(raw, &values)
+		return nil, values, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	keys = []string{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected token %v", tok)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
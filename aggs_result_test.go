@@ -0,0 +1,109 @@
+package osquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestAggregationResults(t *testing.T) {
+	categories := TermsAgg("categories", "category")
+	avgPrice := Avg("avg_price", "price")
+	latency := Percentiles("latency", "took")
+	stats := Stats("price_stats", "price")
+	comments := NestedAgg("comments", "comments")
+	authors := Cardinality("authors", "comments.author")
+	cheap := FilterAgg("cheap", Range("price").Lt(10))
+	newest := TopHits("newest").Size(1)
+
+	categories.Aggs(avgPrice, comments.Aggs(authors))
+	cheap.Aggs(newest)
+
+	aggs, err := ParseAggregations(json.RawMessage(`{
+		"categories": {
+			"doc_count_error_upper_bound": 0,
+			"sum_other_doc_count": 4,
+			"buckets": [
+				{
+					"key": "books",
+					"doc_count": 10,
+					"avg_price": {"value": 12.5},
+					"comments": {"doc_count": 3, "authors": {"value": 2}}
+				},
+				{
+					"key": "games",
+					"doc_count": 0,
+					"avg_price": {"value": null},
+					"comments": {"doc_count": 0, "authors": {"value": 0}}
+				}
+			]
+		},
+		"latency": {"values": {"50.0": 3.5, "99.0": 120, "99.9": null}},
+		"price_stats": {"count": 14, "min": 1, "max": 40, "avg": 10, "sum": 140},
+		"cheap": {
+			"doc_count": 1,
+			"newest": {
+				"hits": {
+					"total": {"value": 1, "relation": "eq"},
+					"max_score": 1,
+					"hits": [{"_index": "items", "_id": "7", "_score": 1, "_source": {"title": "Go and Stuff", "views": 2}}]
+				}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	terms, err := categories.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), terms.SumOtherDocCount)
+	assert.Equal(t, 2, len(terms.Buckets))
+
+	books := terms.Buckets[0]
+	assert.Equal(t, "books", books.Key)
+	assert.Equal(t, int64(10), books.DocCount)
+
+	price, err := avgPrice.Result(books.Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 12.5, *price.Value)
+
+	nested, err := comments.Result(books.Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), nested.DocCount)
+
+	count, err := authors.Result(nested.Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, *count.Value)
+
+	price, err = avgPrice.Result(terms.Buckets[1].Aggs)
+	assert.Nil(t, err)
+	assert.True(t, price.Value == nil)
+
+	percentiles, err := latency.Result(aggs)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, map[float64]float64{50: 3.5, 99: 120}, percentiles.Values)
+
+	priceStats, err := stats.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(14), priceStats.Count)
+	assert.Equal(t, 40.0, *priceStats.Max)
+
+	filtered, err := cheap.Result(aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), filtered.DocCount)
+
+	hits, err := DecodeTopHits[testDoc](newest, filtered.Aggs)
+	assert.Nil(t, err)
+	assert.Equal(t, "7", hits.Hits[0].ID)
+	assert.Equal(t, "Go and Stuff", hits.Hits[0].Source.Title)
+
+	_, err = Max("missing", "price").Result(aggs)
+	assert.NotNil(t, err)
+}
+
+func TestPercentilesResultNonKeyed(t *testing.T) {
+	var res PercentilesResult
+	err := json.Unmarshal([]byte(`{"values": [{"key": 50.0, "value": 3.5}, {"key": 99.0, "value": null}]}`), &res)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, map[float64]float64{50: 3.5}, res.Values)
+}
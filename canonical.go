@@ -0,0 +1,152 @@
+package osquery
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of a query,
+// aggregation or search request. Equivalent trees are encoded to the same
+// bytes, which makes the encoding suitable for cache keys and deduplication:
+//
+//   - object keys are sorted and no insignificant whitespace is written;
+//   - numbers are normalized, so 18, 18.0 and 1.8e1 are all written as 18;
+//   - empty bool queries, which match all documents, are pruned where
+//     removing them does not change the results: as the query or post filter
+//     of a search request, and from the filter clause of bool queries.
+//
+// Queries are written in their long form, as Map returns them, even in
+// search requests using ShortForm.
+func MarshalCanonical(node Mappable) ([]byte, error) {
+	if node != nil {
+		// canonicalize never replaces an aggregation, so Transform cannot fail
+		if pruned, _ := Transform(node, canonicalize); pruned != nil {
+			node = pruned
+		}
+	}
+
+	var m map[string]interface{}
+	if node != nil {
+		m = node.Map()
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeCanonical(&buf, v)
+	return buf.Bytes(), nil
+}
+
+// Fingerprint returns a hash of the canonical JSON encoding of a query,
+// aggregation or search request, as returned by MarshalCanonical, in
+// hexadecimal form. Equivalent trees have the same fingerprint. An empty
+// string is returned if the node cannot be encoded to JSON, which only happens
+// if it holds values such as NaN numbers or channels.
+func Fingerprint(node Mappable) string {
+	data, err := MarshalCanonical(node)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalize prunes the empty bool queries that can be removed from a node
+// without changing the results.
+func canonicalize(node Mappable) Mappable {
+	switch n := node.(type) {
+	case *SearchRequest:
+		// Transform passes a copy of the request, which is written in its
+		// long form whatever the original's setting
+		n.shortForm = false
+		if isEmptyBool(n.query) {
+			n.query = nil
+		}
+		if isEmptyBool(n.postFilter) {
+			n.postFilter = nil
+		}
+	case *BoolQuery:
+		filter := make([]Mappable, 0, len(n.filter))
+		for _, q := range n.filter {
+			if !isEmptyBool(q) {
+				filter = append(filter, q)
+			}
+		}
+		// without must and filter clauses, should clauses become required
+		// unless minimum_should_match is set, so the empty filters are then
+		// kept
+		if len(n.must)+len(filter) > 0 || len(n.should) == 0 || n.minimumShouldMatch != 0 {
+			n.filter = filter
+		}
+	}
+	return node
+}
+
+// isEmptyBool returns whether q is a bool query with no clauses nor
+// parameters, which matches all documents.
+func isEmptyBool(q Mappable) bool {
+	b, ok := q.(*BoolQuery)
+	if !ok {
+		return false
+	}
+	params, _ := b.Map()["bool"].(map[string]interface{})
+	return len(params) == 0
+}
+
+// writeCanonical writes the canonical encoding of a value decoded by
+// decodeJSON.
+func writeCanonical(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonical(buf, key)
+			buf.WriteByte(':')
+			writeCanonical(buf, v[key])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonical(buf, e)
+		}
+		buf.WriteByte(']')
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			buf.WriteString(strconv.FormatInt(int64(v), 10))
+		} else {
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	default:
+		// strings, booleans and null are encoded by encoding/json, which
+		// cannot fail for those
+		data, _ := json.Marshal(v)
+		buf.Write(data)
+	}
+}
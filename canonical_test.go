@@ -0,0 +1,96 @@
+package osquery
+
+import (
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		node Mappable
+		exp  string
+	}{
+		{
+			"sorted keys",
+			Range("age").Lte(65).Gte(18),
+			`{"range":{"age":{"gte":18,"lte":65}}}`,
+		},
+		{
+			"normalized numbers",
+			CustomQuery(map[string]interface{}{
+				"a": 18.0,
+				"b": float32(0.5),
+				"c": uint8(3),
+				"d": 1.5e-7,
+				"e": 1e21,
+			}),
+			`{"a":18,"b":0.5,"c":3,"d":1.5e-07,"e":1e+21}`,
+		},
+		{
+			"empty bool pruned from search request",
+			Search().Query(Bool()).PostFilter(Bool()).Size(10),
+			`{"size":10}`,
+		},
+		{
+			"empty bools pruned from filter clauses",
+			Search().Query(Bool().Filter(Bool(), Term("user", "kimchy"), Bool().Filter(Bool()))),
+			`{"query":{"bool":{"filter":[{"term":{"user":{"value":"kimchy"}}}]}}}`,
+		},
+		{
+			"empty bools kept where they change results",
+			Bool().MustNot(Bool()).Filter(Bool().Boost(2)),
+			`{"bool":{"filter":[{"bool":{"boost":2}}],"must_not":[{"bool":{}}]}}`,
+		},
+		{
+			"empty filters kept when should clauses would become required",
+			Bool().Should(Term("a", "x")).Filter(Bool()),
+			`{"bool":{"filter":[{"bool":{}}],"should":[{"term":{"a":{"value":"x"}}}]}}`,
+		},
+		{
+			"empty filters pruned alongside should clauses when results are unchanged",
+			Bool().Should(Term("a", "x")).Filter(Bool(), Term("b", "y")).MinimumShouldMatch(1),
+			`{"bool":{"filter":[{"term":{"b":{"value":"y"}}}],"minimum_should_match":1,"should":[{"term":{"a":{"value":"x"}}}]}}`,
+		},
+		{
+			"nil node",
+			nil,
+			`null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := MarshalCanonical(test.node)
+			assert.Nil(t, err)
+			assert.Equal(t, test.exp, string(data))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	q := Bool().Filter(Term("user", "kimchy"))
+	req := Search().Query(q).Size(10)
+
+	assert.Equal(t, 64, len(Fingerprint(req)))
+	assert.Equal(t, Fingerprint(req), Fingerprint(req))
+	assert.Equal(t, Fingerprint(req), Fingerprint(Search().Size(10).Query(Bool().Filter(Bool(), Term("user", "kimchy")))))
+	assert.Equal(t, Fingerprint(Search()), Fingerprint(Search().Query(Bool())))
+	assert.NotEqual(t, Fingerprint(req), Fingerprint(Search().Query(q).Size(20)))
+	assert.NotEqual(t, Fingerprint(req), Fingerprint(q))
+	assert.NotEqual(t,
+		Fingerprint(Bool().Should(Term("a", "x")).Filter(Bool())),
+		Fingerprint(Bool().Should(Term("a", "x"))),
+	)
+
+	// short and long forms of a request are equivalent
+	long := Fingerprint(req)
+	assert.Equal(t, long, Fingerprint(req.ShortForm(true)))
+	assert.True(t, req.shortForm)
+
+	// the query itself is left untouched
+	withEmpty := Bool().Filter(Bool())
+	Fingerprint(withEmpty)
+	assertJSON(t, `{"bool": {"filter": [{"bool": {}}]}}`, withEmpty.Map())
+}
@@ -0,0 +1,80 @@
+// Command osquerygen reads the JSON representation of an OpenSearch query,
+// aggregations or search request and prints Go code building it with
+// osquery. Fragments the library does not support are generated as
+// osquery.CustomQuery and osquery.CustomAgg calls.
+//
+// Usage:
+//
+//	osquerygen [-type query|aggs|search] [file ...]
+//
+// The JSON is read from the files provided, or from standard input if there
+// are none.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/defensestation/osquery/v2"
+)
+
+var generators = map[string]func([]byte) (string, error){
+	"query":  osquery.GenerateQuery,
+	"aggs":   osquery.GenerateAggs,
+	"search": osquery.GenerateSearchRequest,
+}
+
+func main() {
+	typ := flag.String("type", "query", "type of the JSON document: query, aggs or search")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: osquerygen [-type query|aggs|search] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	generate, ok := generators[*typ]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "osquerygen: unknown type %q\n", *typ)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		if err := run(generate, "<stdin>", os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "osquerygen: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	failed := false
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err == nil {
+			err = run(generate, name, f)
+			f.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "osquerygen: %s\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run(generate func([]byte) (string, error), name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	code, err := generate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	fmt.Println(code)
+	return nil
+}
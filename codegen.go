@@ -0,0 +1,962 @@
+package osquery
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateQuery parses the JSON representation of a query, as ParseQuery
+// does, and returns Go source code building it with the library, such as
+// osquery.Bool().Must(osquery.Term("user", "kimchy")).
+//
+// Only the bool, boosting, constant_score, dis_max, nested, match,
+// match_bool_prefix, match_phrase, match_phrase_prefix, multi_match,
+// match_all, match_none, term, terms, terms_set, exists, ids, prefix, range,
+// regexp, wildcard, fuzzy and knn query types are generated with their
+// builders. Other types, such as query_string, simple_query_string,
+// function_score, script_score, has_child or geo queries, and queries using
+// parameters the builders cannot represent, are generated as
+// osquery.CustomQuery calls holding their JSON as a map literal.
+func GenerateQuery(data []byte) (string, error) {
+	q, err := ParseQuery(data)
+	if err != nil {
+		return "", err
+	}
+	return formatCode(queryCode(q))
+}
+
+// GenerateAggs parses the JSON representation of an object of named
+// aggregations, as found in the "aggs" section of a search request, and
+// returns Go source code of a []osquery.Aggregation literal building them.
+//
+// Only the terms, date_histogram, histogram, filter, nested, avg, max, min,
+// sum, stats, value_count and cardinality aggregations are generated with
+// their builders. Other types, such as range, date_range, composite,
+// significant_terms, top_hits or pipeline aggregations, and aggregations
+// using parameters the builders cannot represent, are generated as
+// osquery.CustomAgg calls.
+func GenerateAggs(data []byte) (string, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return "", err
+	}
+	aggs, err := parseAggs(v, "aggs")
+	if err != nil {
+		return "", err
+	}
+
+	lst := composite("[]osquery.Aggregation")
+	for _, agg := range aggs {
+		lst.args = append(lst.args, aggCode(agg))
+	}
+	return formatCode(lst)
+}
+
+// GenerateSearchRequest parses the JSON body of a search request, as
+// ParseSearchRequest does, and returns Go source code building it with the
+// library, starting with osquery.Search(). Its queries and aggregations are
+// generated as GenerateQuery and GenerateAggs do, and fields SearchRequest
+// does not support as calls to its Custom method.
+func GenerateSearchRequest(data []byte) (string, error) {
+	req, err := ParseSearchRequest(data)
+	if err != nil {
+		return "", err
+	}
+	return formatCode(searchCode(req))
+}
+
+// formatCode renders a Go expression and formats it as gofmt does.
+func formatCode(x *goExpr) (string, error) {
+	src, err := format.Source([]byte(x.render(0)))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return string(src), nil
+}
+
+//----------------------------------------------------------------------------//
+
+func searchCode(req *SearchRequest) *goExpr {
+	x := call("osquery.Search")
+	if req.query != nil {
+		x.chain("Query", queryCode(req.query))
+	}
+	if req.postFilter != nil {
+		x.chain("PostFilter", queryCode(req.postFilter))
+	}
+	if len(req.aggs) > 0 {
+		x.chain("Aggs", aggsCode(req.aggs)...)
+	}
+	if req.size != nil {
+		x.chain("Size", lit(strconv.FormatUint(*req.size, 10)))
+	}
+	if req.from != nil {
+		x.chain("From", lit(strconv.FormatUint(*req.from, 10)))
+	}
+	if req.explain != nil {
+		x.chain("Explain", lit(strconv.FormatBool(*req.explain)))
+	}
+	if req.timeout != nil {
+		x.chain("Timeout", durationCode(*req.timeout))
+	}
+	if req.searchAfter != nil {
+		x.chain("SearchAfter", valuesCode(req.searchAfter)...)
+	}
+	if len(req.sort) > 0 {
+		opts := make([]*goExpr, len(req.sort))
+		for i, opt := range req.sort {
+			opts[i] = sortCode(opt)
+		}
+		x.chain("Sort", opts...)
+	}
+	if len(req.source.includes) > 0 {
+		x.chain("SourceIncludes", stringsCode(req.source.includes)...)
+	}
+	if len(req.source.excludes) > 0 {
+		x.chain("SourceExcludes", stringsCode(req.source.excludes)...)
+	}
+	if req.source.disabled {
+		x.chain("DisableSource")
+	}
+	if req.highlight != nil {
+		x.chain("Highlight", queryCode(req.highlight))
+	}
+	if req.pit != nil {
+		x.chain("PIT", stringCode(req.pit.id), durationCode(req.pit.keepAlive))
+	}
+	if len(req.scriptFields) > 0 {
+		scripts := make([]*goExpr, len(req.scriptFields))
+		for i, script := range req.scriptFields {
+			scripts[i] = scriptCode(script)
+		}
+		x.chain("ScriptFields", scripts...)
+	}
+	for _, key := range sortedKeys(req.custom) {
+		x.chain("Custom", stringCode(key), valueCode(req.custom[key]))
+	}
+	return x
+}
+
+func sortCode(opt SortOption) *goExpr {
+	f, ok := opt.(*FieldSortOption)
+	if !ok {
+		return call("osquery.CustomQuery", valueCode(opt.Map()))
+	}
+
+	x := call("osquery.FieldSort", stringCode(f.field))
+	if f.order != "" {
+		x.chain("Order", enumCode(map[Order]string{
+			OrderAsc:  "OrderAsc",
+			OrderDesc: "OrderDesc",
+		}, f.order, "Order"))
+	}
+	if f.mode != "" {
+		x.chain("Mode", enumCode(map[Mode]string{
+			SortModeMin:    "SortModeMin",
+			SortModeMax:    "SortModeMax",
+			SortModeSum:    "SortModeSum",
+			SortModeAvg:    "SortModeAvg",
+			SortModeMedian: "SortModeMedian",
+		}, f.mode, "Mode"))
+	}
+	if f.missing != "" {
+		x.chain("Missing", enumCode(map[Missing]string{
+			MissingLast:  "MissingLast",
+			MissingFirst: "MissingFirst",
+		}, f.missing, "Missing"))
+	}
+	if f.nestedPath != "" {
+		x.chain("NestedPath", stringCode(f.nestedPath))
+	}
+	if f.nestedFilter != nil {
+		x.chain("NestedFilter", queryCode(f.nestedFilter))
+	}
+	return x
+}
+
+func scriptCode(script *ScriptField) *goExpr {
+	x := call("osquery.Script", stringCode(script.name))
+	if script.Src != "" {
+		x.chain("Source", stringCode(script.Src))
+	}
+	if script.Param != nil {
+		x.chain("Params", valueCode(map[string]interface{}(script.Param)))
+	}
+	if script.Id != "" {
+		x.chain("ID", stringCode(script.Id))
+	}
+	if script.Language != "" {
+		x.chain("Lang", stringCode(script.Language))
+	}
+	return x
+}
+
+//----------------------------------------------------------------------------//
+
+// queryCode returns the code building a query. Types that are not supported
+// are generated as custom queries.
+func queryCode(q Mappable) *goExpr {
+	switch q := q.(type) {
+	case *BoolQuery:
+		x := call("osquery.Bool")
+		for _, clause := range []struct {
+			method  string
+			queries []Mappable
+		}{
+			{"Must", q.must},
+			{"Filter", q.filter},
+			{"MustNot", q.mustNot},
+			{"Should", q.should},
+		} {
+			if len(clause.queries) > 0 {
+				x.chain(clause.method, queriesCode(clause.queries)...)
+			}
+		}
+		if q.minimumShouldMatch != 0 {
+			x.chain("MinimumShouldMatch", lit(strconv.Itoa(int(q.minimumShouldMatch))))
+		}
+		if q.boost != 0 {
+			x.chain("Boost", float32Code(q.boost))
+		}
+		if q.name != "" {
+			x.chain("Name", stringCode(q.name))
+		}
+		return x
+
+	case *BoostingQuery:
+		x := call("osquery.Boosting")
+		if q.Pos != nil {
+			x.chain("Positive", queryCode(q.Pos))
+		}
+		if q.Neg != nil {
+			x.chain("Negative", queryCode(q.Neg))
+		}
+		return x.chain("NegativeBoost", float32Code(q.NegBoost))
+
+	case *ConstantScoreQuery:
+		x := call("osquery.ConstantScore", queryCode(q.filter))
+		if q.boost != 0 {
+			x.chain("Boost", float32Code(q.boost))
+		}
+		if q.name != "" {
+			x.chain("Name", stringCode(q.name))
+		}
+		return x
+
+	case *DisMaxQuery:
+		x := call("osquery.DisMax", queriesCode(q.queries)...)
+		if q.tieBreaker != 0 {
+			x.chain("TieBreaker", float32Code(q.tieBreaker))
+		}
+		return x
+
+	case *NestedQuery:
+		x := call("osquery.Nested", stringCode(q.path), queryCode(q.query))
+		if q.scoreMode != "" {
+			x.chain("ScoreMode", enumCode(map[ScoreModeType]string{
+				ScoreModeAvg:  "ScoreModeAvg",
+				ScoreModeMax:  "ScoreModeMax",
+				ScoreModeMin:  "ScoreModeMin",
+				ScoreModeSum:  "ScoreModeSum",
+				ScoreModeNone: "ScoreModeNone",
+			}, ScoreModeType(q.scoreMode), "ScoreModeType"))
+		}
+		if q.innerHits != nil {
+			x.chain("InnerHits", valueCode(q.innerHits))
+		}
+		return x
+
+	case *MatchQuery:
+		return matchCode(q)
+
+	case *MultiMatchQuery:
+		return multiMatchCode(q)
+
+	case *MatchAllQuery:
+		x := call("osquery.MatchNone")
+		if q.all {
+			x = call("osquery.MatchAll")
+		}
+		if q.params.Boost != 0 {
+			x.chain("Boost", float32Code(q.params.Boost))
+		}
+		return x
+
+	case *TermQuery:
+		x := call("osquery.Term", stringCode(q.field), valueCode(q.params.Value))
+		if q.params.Boost != 0 {
+			x.chain("Boost", float32Code(q.params.Boost))
+		}
+		if q.params.CaseInsensitive {
+			x.chain("CaseInsensitive", lit("true"))
+		}
+		if q.params.Name != "" {
+			x.chain("Name", stringCode(q.params.Name))
+		}
+		return x
+
+	case *TermsQuery:
+		values := spreadCode("[]interface{}", valuesCode(q.values))
+		x := call("osquery.Terms", append([]*goExpr{stringCode(q.field)}, values...)...)
+		if q.boost > 0 {
+			x.chain("Boost", float32Code(q.boost))
+		}
+		return x
+
+	case *TermsSetQuery:
+		terms := spreadCode("[]string", stringsCode(q.params.Terms))
+		x := call("osquery.TermsSet", append([]*goExpr{stringCode(q.field)}, terms...)...)
+		if q.params.MinimumShouldMatchField != "" {
+			x.chain("MinimumShouldMatchField", stringCode(q.params.MinimumShouldMatchField))
+		}
+		if q.params.MinimumShouldMatchScript != "" {
+			x.chain("MinimumShouldMatchScript", stringCode(q.params.MinimumShouldMatchScript))
+		}
+		return x
+
+	case *ExistsQuery:
+		return call("osquery.Exists", stringCode(q.Field))
+
+	case *IDsQuery:
+		return call("osquery.IDs", spreadCode("[]string", stringsCode(q.IDs.Values))...)
+
+	case *PrefixQuery:
+		x := call("osquery.Prefix", stringCode(q.field), stringCode(q.params.Value))
+		if q.params.Rewrite != "" {
+			x.chain("Rewrite", stringCode(q.params.Rewrite))
+		}
+		return x
+
+	case *RangeQuery:
+		x := call("osquery.Range", stringCode(q.field))
+		for _, bound := range []struct {
+			method string
+			value  interface{}
+		}{
+			{"Gt", q.params.Gt},
+			{"Gte", q.params.Gte},
+			{"Lt", q.params.Lt},
+			{"Lte", q.params.Lte},
+		} {
+			if bound.value != nil {
+				x.chain(bound.method, valueCode(bound.value))
+			}
+		}
+		if q.params.Format != "" {
+			x.chain("Format", stringCode(q.params.Format))
+		}
+		if q.params.Relation != 0 {
+			x.chain("Relation", enumCode(map[RangeRelation]string{
+				RangeIntersects: "RangeIntersects",
+				RangeContains:   "RangeContains",
+				RangeWithin:     "RangeWithin",
+			}, q.params.Relation, "RangeRelation"))
+		}
+		if q.params.TimeZone != "" {
+			x.chain("TimeZone", stringCode(q.params.TimeZone))
+		}
+		if q.params.Boost != 0 {
+			x.chain("Boost", float32Code(q.params.Boost))
+		}
+		return x
+
+	case *RegexpQuery:
+		fn := "osquery.Regexp"
+		if q.wildcard {
+			fn = "osquery.Wildcard"
+		}
+		x := call(fn, stringCode(q.field), stringCode(q.params.Value))
+		if q.params.Flags != "" {
+			x.chain("Flags", stringCode(q.params.Flags))
+		}
+		if q.params.MaxDeterminizedStates != 0 {
+			x.chain("MaxDeterminizedStates", uintCode(uint64(q.params.MaxDeterminizedStates)))
+		}
+		if q.params.Rewrite != "" {
+			x.chain("Rewrite", stringCode(q.params.Rewrite))
+		}
+		return x
+
+	case *FuzzyQuery:
+		x := call("osquery.Fuzzy", stringCode(q.field), stringCode(q.params.Value))
+		if q.params.Fuzziness != "" {
+			x.chain("Fuzziness", stringCode(q.params.Fuzziness))
+		}
+		if q.params.MaxExpansions != 0 {
+			x.chain("MaxExpansions", uintCode(uint64(q.params.MaxExpansions)))
+		}
+		if q.params.PrefixLength != 0 {
+			x.chain("PrefixLength", uintCode(uint64(q.params.PrefixLength)))
+		}
+		if q.params.Transpositions != nil {
+			x.chain("Transpositions", lit(strconv.FormatBool(*q.params.Transpositions)))
+		}
+		if q.params.Rewrite != "" {
+			x.chain("Rewrite", stringCode(q.params.Rewrite))
+		}
+		return x
+
+	case *KNNQuery:
+		vector := composite("[]float64")
+		for _, f := range q.vector {
+			vector.args = append(vector.args, floatCode(f))
+		}
+		x := call("osquery.KNN", stringCode(q.field), vector)
+		if q.k != nil {
+			x.chain("K", lit(strconv.Itoa(*q.k)))
+		}
+		if q.maxDistance != nil {
+			x.chain("MaxDistance", floatCode(*q.maxDistance))
+		}
+		if q.minScore != nil {
+			x.chain("MinScore", floatCode(*q.minScore))
+		}
+		if q.filter != nil {
+			x.chain("Filter", valueCode(q.filter))
+		}
+		if q.methodParameters != nil {
+			x.chain("MethodParameters", valueCode(q.methodParameters))
+		}
+		if q.rescore != nil {
+			x.chain("Rescore", valueCode(q.rescore))
+		}
+		if q.expandNestedDocs != nil {
+			x.chain("ExpandNestedDocs", lit(strconv.FormatBool(*q.expandNestedDocs)))
+		}
+		return x
+
+	case nil:
+		return lit("nil")
+	}
+	return call("osquery.CustomQuery", valueCode(q.Map()))
+}
+
+func queriesCode(queries []Mappable) []*goExpr {
+	code := make([]*goExpr, len(queries))
+	for i, q := range queries {
+		code[i] = queryCode(q)
+	}
+	return code
+}
+
+func matchCode(q *MatchQuery) *goExpr {
+	fn := map[matchType]string{
+		TypeMatch:             "osquery.Match",
+		TypeMatchBoolPrefix:   "osquery.MatchBoolPrefix",
+		TypeMatchPhrase:       "osquery.MatchPhrase",
+		TypeMatchPhrasePrefix: "osquery.MatchPhrasePrefix",
+	}[q.mType]
+
+	x := call(fn, stringCode(q.field))
+	if q.params.Qry != nil {
+		x.args = append(x.args, valueCode(q.params.Qry))
+	}
+
+	p := q.params
+	if p.Name != "" {
+		x.chain("Name", stringCode(p.Name))
+	}
+	if p.Anl != "" {
+		x.chain("Analyzer", stringCode(p.Anl))
+	}
+	if p.AutoGenerate != nil {
+		x.chain("AutoGenerateSynonymsPhraseQuery", lit(strconv.FormatBool(*p.AutoGenerate)))
+	}
+	if p.Fuzz != "" {
+		x.chain("Fuzziness", stringCode(p.Fuzz))
+	}
+	if p.MaxExp != 0 {
+		x.chain("MaxExpansions", uintCode(uint64(p.MaxExp)))
+	}
+	if p.PrefLen != 0 {
+		x.chain("PrefixLength", uintCode(uint64(p.PrefLen)))
+	}
+	if p.FuzzyTranspositions != nil {
+		x.chain("FuzzyTranspositions", lit(strconv.FormatBool(*p.FuzzyTranspositions)))
+	}
+	if p.FuzzyRw != "" {
+		x.chain("FuzzyRewrite", stringCode(p.FuzzyRw))
+	}
+	if p.Lent {
+		x.chain("Lenient", lit("true"))
+	}
+	if p.Op != OperatorOr {
+		x.chain("Operator", operatorCode(p.Op))
+	}
+	if p.MinMatch != "" {
+		x.chain("MinimumShouldMatch", stringCode(p.MinMatch))
+	}
+	if p.ZeroTerms != ZeroTermsNone {
+		x.chain("ZeroTermsQuery", zeroTermsCode(p.ZeroTerms))
+	}
+	if p.Slp != 0 {
+		x.chain("Slop", uintCode(uint64(p.Slp)))
+	}
+	return x
+}
+
+func multiMatchCode(q *MultiMatchQuery) *goExpr {
+	p := q.params
+	x := call("osquery.MultiMatch")
+	if p.Qry != nil {
+		x.args = append(x.args, valueCode(p.Qry))
+	}
+	if p.Fields != nil {
+		x.chain("Fields", stringsCode(p.Fields)...)
+	}
+	if p.Type != MatchTypeBestFields {
+		x.chain("Type", enumCode(map[MultiMatchType]string{
+			MatchTypeMostFields:   "MatchTypeMostFields",
+			MatchTypeCrossFields:  "MatchTypeCrossFields",
+			MatchTypePhrase:       "MatchTypePhrase",
+			MatchTypePhrasePrefix: "MatchTypePhrasePrefix",
+			MatchTypeBoolPrefix:   "MatchTypeBoolPrefix",
+		}, p.Type, "MultiMatchType"))
+	}
+	if p.TieBrk != 0 {
+		x.chain("TieBreaker", float32Code(p.TieBrk))
+	}
+	if p.Boost != 0 {
+		x.chain("Boost", float32Code(p.Boost))
+	}
+	if p.Name != "" {
+		x.chain("Name", stringCode(p.Name))
+	}
+	if p.Anl != "" {
+		x.chain("Analyzer", stringCode(p.Anl))
+	}
+	if p.AutoGenerate != nil {
+		x.chain("AutoGenerateSynonymsPhraseQuery", lit(strconv.FormatBool(*p.AutoGenerate)))
+	}
+	if p.Fuzz != "" {
+		x.chain("Fuzziness", stringCode(p.Fuzz))
+	}
+	if p.MaxExp != 0 {
+		x.chain("MaxExpansions", uintCode(uint64(p.MaxExp)))
+	}
+	if p.PrefLen != 0 {
+		x.chain("PrefixLength", uintCode(uint64(p.PrefLen)))
+	}
+	if p.FuzzyTranspositions != nil {
+		x.chain("FuzzyTranspositions", lit(strconv.FormatBool(*p.FuzzyTranspositions)))
+	}
+	if p.FuzzyRw != "" {
+		x.chain("FuzzyRewrite", stringCode(p.FuzzyRw))
+	}
+	if p.Lent != nil {
+		x.chain("Lenient", lit(strconv.FormatBool(*p.Lent)))
+	}
+	if p.Op != OperatorOr {
+		x.chain("Operator", operatorCode(p.Op))
+	}
+	if p.MinMatch != "" {
+		x.chain("MinimumShouldMatch", stringCode(p.MinMatch))
+	}
+	if p.ZeroTerms != ZeroTermsNone {
+		x.chain("ZeroTermsQuery", zeroTermsCode(p.ZeroTerms))
+	}
+	if p.Slp != 0 {
+		x.chain("Slop", uintCode(uint64(p.Slp)))
+	}
+	return x
+}
+
+func operatorCode(op MatchOperator) *goExpr {
+	return enumCode(map[MatchOperator]string{
+		OperatorOr:  "OperatorOr",
+		OperatorAnd: "OperatorAnd",
+	}, op, "MatchOperator")
+}
+
+func zeroTermsCode(zt ZeroTerms) *goExpr {
+	return enumCode(map[ZeroTerms]string{
+		ZeroTermsNone: "ZeroTermsNone",
+		ZeroTermsAll:  "ZeroTermsAll",
+	}, zt, "ZeroTerms")
+}
+
+//----------------------------------------------------------------------------//
+
+// aggCode returns the code building an aggregation. Types that are not
+// supported are generated as custom aggregations.
+func aggCode(agg Aggregation) *goExpr {
+	name := stringCode(agg.Name())
+	switch agg := agg.(type) {
+	case *TermsAggregation:
+		x := call("osquery.TermsAgg", name, stringCode(agg.field))
+		if agg.size != nil {
+			x.chain("Size", uintCode(*agg.size))
+		}
+		if agg.shardSize != nil {
+			x.chain("ShardSize", floatCode(*agg.shardSize))
+		}
+		if agg.showTermDoc != nil {
+			x.chain("ShowTermDocCountError", lit(strconv.FormatBool(*agg.showTermDoc)))
+		}
+		if agg.order != nil {
+			x.chain("Order", valueCode(agg.order))
+		}
+		if agg.include != nil {
+			x.chain("Include", stringsCode(agg.include)...)
+		}
+		return subAggsCode(x, agg.aggs)
+
+	case *DateHistogramAggregation:
+		x := call("osquery.DateHistogramAgg", name, stringCode(agg.field))
+		for _, param := range []struct {
+			method string
+			value  string
+		}{
+			{"CalendarInterval", agg.calendarInterval},
+			{"FixedInterval", agg.fixedInterval},
+			{"TimeZone", agg.timeZone},
+			{"Offset", agg.offset},
+			{"Format", agg.format},
+		} {
+			if param.value != "" {
+				x.chain(param.method, stringCode(param.value))
+			}
+		}
+		if agg.minDocCount != nil {
+			x.chain("MinDocCount", uintCode(*agg.minDocCount))
+		}
+		if agg.extendedBounds != nil {
+			x.chain("ExtendedBounds", valueCode(agg.extendedBounds["min"]), valueCode(agg.extendedBounds["max"]))
+		}
+		if agg.hardBounds != nil {
+			x.chain("HardBounds", valueCode(agg.hardBounds["min"]), valueCode(agg.hardBounds["max"]))
+		}
+		if agg.keyed != nil {
+			x.chain("Keyed", lit(strconv.FormatBool(*agg.keyed)))
+		}
+		if agg.missing != nil {
+			x.chain("Missing", valueCode(agg.missing))
+		}
+		if agg.order != nil {
+			x.chain("Order", valueCode(agg.order))
+		}
+		return subAggsCode(x, agg.aggs)
+
+	case *HistogramAggregation:
+		x := call("osquery.HistogramAgg", name, stringCode(agg.field), floatCode(agg.interval))
+		if agg.offset != nil {
+			x.chain("Offset", floatCode(*agg.offset))
+		}
+		if agg.minDocCount != nil {
+			x.chain("MinDocCount", uintCode(*agg.minDocCount))
+		}
+		if agg.extendedBounds != nil {
+			x.chain("ExtendedBounds", floatCode(agg.extendedBounds["min"]), floatCode(agg.extendedBounds["max"]))
+		}
+		if agg.hardBounds != nil {
+			x.chain("HardBounds", floatCode(agg.hardBounds["min"]), floatCode(agg.hardBounds["max"]))
+		}
+		if agg.keyed != nil {
+			x.chain("Keyed", lit(strconv.FormatBool(*agg.keyed)))
+		}
+		if agg.missing != nil {
+			x.chain("Missing", valueCode(agg.missing))
+		}
+		if agg.order != nil {
+			x.chain("Order", valueCode(agg.order))
+		}
+		return subAggsCode(x, agg.aggs)
+
+	case *FilterAggregation:
+		return subAggsCode(call("osquery.FilterAgg", name, queryCode(agg.filter)), agg.aggs)
+
+	case *NestedAggregation:
+		return subAggsCode(call("osquery.NestedAgg", name, stringCode(agg.path)), agg.aggs)
+
+	case *AvgAgg:
+		return metricAggCode("osquery.Avg", name, agg.BaseAgg)
+	case *MaxAgg:
+		return metricAggCode("osquery.Max", name, agg.BaseAgg)
+	case *MinAgg:
+		return metricAggCode("osquery.Min", name, agg.BaseAgg)
+	case *SumAgg:
+		return metricAggCode("osquery.Sum", name, agg.BaseAgg)
+	case *StatsAgg:
+		return metricAggCode("osquery.Stats", name, agg.BaseAgg)
+	case *ValueCountAgg:
+		return metricAggCode("osquery.ValueCount", name, agg.BaseAgg)
+	case *CardinalityAgg:
+		x := metricAggCode("osquery.Cardinality", name, agg.BaseAgg)
+		if agg.PrecisionThr != 0 {
+			x.chain("PrecisionThreshold", uintCode(uint64(agg.PrecisionThr)))
+		}
+		return x
+
+	case *CustomAggMap:
+		return call("osquery.CustomAgg", name, valueCode(agg.agg))
+	}
+	return call("osquery.CustomAgg", name, valueCode(agg.Map()))
+}
+
+func aggsCode(aggs []Aggregation) []*goExpr {
+	code := make([]*goExpr, len(aggs))
+	for i, agg := range aggs {
+		code[i] = aggCode(agg)
+	}
+	return code
+}
+
+func subAggsCode(x *goExpr, aggs []Aggregation) *goExpr {
+	if len(aggs) > 0 {
+		x.chain("Aggs", aggsCode(aggs)...)
+	}
+	return x
+}
+
+func metricAggCode(fn string, name *goExpr, agg *BaseAgg) *goExpr {
+	x := call(fn, name, stringCode(agg.Field))
+	if agg.Miss != nil {
+		x.chain("Missing", valueCode(agg.Miss))
+	}
+	return x
+}
+
+//----------------------------------------------------------------------------//
+
+func stringCode(s string) *goExpr {
+	return lit(strconv.Quote(s))
+}
+
+func stringsCode(list []string) []*goExpr {
+	code := make([]*goExpr, len(list))
+	for i, s := range list {
+		code[i] = stringCode(s)
+	}
+	return code
+}
+
+func uintCode(n uint64) *goExpr {
+	return lit(strconv.FormatUint(n, 10))
+}
+
+func floatCode(f float64) *goExpr {
+	return lit(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func float32Code(f float32) *goExpr {
+	return lit(strconv.FormatFloat(float64(f), 'g', -1, 32))
+}
+
+// durationCode returns the code of a duration, in the largest unit it is a
+// whole number of.
+func durationCode(d time.Duration) *goExpr {
+	for _, unit := range []struct {
+		name string
+		dur  time.Duration
+	}{
+		{"time.Hour", time.Hour},
+		{"time.Minute", time.Minute},
+		{"time.Second", time.Second},
+		{"time.Millisecond", time.Millisecond},
+	} {
+		if d != 0 && d%unit.dur == 0 {
+			if d == unit.dur {
+				return lit(unit.name)
+			}
+			return lit(fmt.Sprintf("%d * %s", d/unit.dur, unit.name))
+		}
+	}
+	return lit(fmt.Sprintf("time.Duration(%d)", int64(d)))
+}
+
+// enumCode returns the name of the constant of an enumeration value, or a
+// conversion of the value to the enumeration type if it has none.
+func enumCode[E comparable](names map[E]string, v E, typ string) *goExpr {
+	if name, ok := names[v]; ok {
+		return lit("osquery." + name)
+	}
+	return lit(fmt.Sprintf("osquery.%s(%#v)", typ, v))
+}
+
+// spreadCode returns the arguments of a variadic call, or an empty slice of
+// the provided type spread into the call if there are none, so that the list
+// is rendered as empty rather than null.
+func spreadCode(typ string, args []*goExpr) []*goExpr {
+	if len(args) == 0 {
+		return []*goExpr{lit(typ + "{}...")}
+	}
+	return args
+}
+
+func valuesCode(values []interface{}) []*goExpr {
+	code := make([]*goExpr, len(values))
+	for i, v := range values {
+		code[i] = valueCode(v)
+	}
+	return code
+}
+
+// valueCode returns the code of an arbitrary value, such as the value of a
+// term query or a custom query map.
+func valueCode(v interface{}) *goExpr {
+	switch v := v.(type) {
+	case nil:
+		return lit("nil")
+	case string:
+		return stringCode(v)
+	case float64:
+		return floatCode(v)
+	case float32:
+		return float32Code(v)
+	case []interface{}:
+		x := composite("[]interface{}")
+		x.args = valuesCode(v)
+		return x
+	case []string:
+		x := composite("[]string")
+		x.args = stringsCode(v)
+		return x
+	case map[string]interface{}:
+		x := composite("map[string]interface{}")
+		for _, key := range sortedKeys(v) {
+			elem := valueCode(v[key])
+			elem.key = strconv.Quote(key)
+			x.args = append(x.args, elem)
+		}
+		return x
+	case map[string]string:
+		x := composite("map[string]string")
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			elem := stringCode(v[key])
+			elem.key = strconv.Quote(key)
+			x.args = append(x.args, elem)
+		}
+		return x
+	}
+	// booleans, integers and other values decoded from JSON
+	return lit(fmt.Sprintf("%#v", v))
+}
+
+//----------------------------------------------------------------------------//
+
+// goExpr is a Go expression being generated: a literal, or a function call
+// or composite literal, followed by chained method calls.
+type goExpr struct {
+	key       string // key of a map literal element
+	lit       string
+	fn        string // called function, or type of a composite literal
+	composite bool
+	args      []*goExpr // arguments, or elements of a composite literal
+	calls     []goCall
+}
+
+type goCall struct {
+	method string
+	args   []*goExpr
+}
+
+// maxLineWidth is the width under which expressions are kept on one line,
+// counting tabs as four columns.
+const maxLineWidth = 80
+
+func lit(code string) *goExpr {
+	return &goExpr{lit: code}
+}
+
+func call(fn string, args ...*goExpr) *goExpr {
+	return &goExpr{fn: fn, args: args}
+}
+
+func composite(typ string) *goExpr {
+	return &goExpr{fn: typ, composite: true}
+}
+
+// chain adds a method call to the expression.
+func (x *goExpr) chain(method string, args ...*goExpr) *goExpr {
+	x.calls = append(x.calls, goCall{method, args})
+	return x
+}
+
+// render returns the code of the expression, which starts at the provided
+// indentation level. Expressions too long for a line are split with one
+// method call or argument per line.
+func (x *goExpr) render(indent int) string {
+	if line := x.line(); indent*4+len(line) <= maxLineWidth {
+		return line
+	}
+
+	var b strings.Builder
+	if x.key != "" {
+		b.WriteString(x.key + ": ")
+	}
+	if x.lit != "" {
+		b.WriteString(x.lit)
+	} else {
+		b.WriteString(x.fn)
+		writeArgs(&b, x.args, x.composite, indent)
+	}
+	for _, c := range x.calls {
+		b.WriteString(".\n" + strings.Repeat("\t", indent+1) + c.method)
+		writeArgs(&b, c.args, false, indent+1)
+	}
+	return b.String()
+}
+
+// line returns the code of the expression on a single line.
+func (x *goExpr) line() string {
+	var b strings.Builder
+	if x.key != "" {
+		b.WriteString(x.key + ": ")
+	}
+	if x.lit != "" {
+		b.WriteString(x.lit)
+	} else {
+		b.WriteString(x.fn + openDelim(x.composite) + argsLine(x.args) + closeDelim(x.composite))
+	}
+	for _, c := range x.calls {
+		b.WriteString("." + c.method + "(" + argsLine(c.args) + ")")
+	}
+	return b.String()
+}
+
+func argsLine(args []*goExpr) string {
+	lines := make([]string, len(args))
+	for i, arg := range args {
+		lines[i] = arg.line()
+	}
+	return strings.Join(lines, ", ")
+}
+
+// writeArgs writes the arguments of a call, or the elements of a composite
+// literal, on the current line if they fit, and one per line otherwise.
+func writeArgs(b *strings.Builder, args []*goExpr, composite bool, indent int) {
+	b.WriteString(openDelim(composite))
+	if line := argsLine(args); indent*4+len(line) <= maxLineWidth {
+		b.WriteString(line)
+	} else {
+		b.WriteString("\n")
+		for _, arg := range args {
+			b.WriteString(strings.Repeat("\t", indent+1) + arg.render(indent+1) + ",\n")
+		}
+		b.WriteString(strings.Repeat("\t", indent))
+	}
+	b.WriteString(closeDelim(composite))
+}
+
+func openDelim(composite bool) string {
+	if composite {
+		return "{"
+	}
+	return "("
+}
+
+func closeDelim(composite bool) string {
+	if composite {
+		return "}"
+	}
+	return ")"
+}
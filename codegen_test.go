@@ -0,0 +1,174 @@
+package osquery
+
+import (
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestGenerateQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		exp  string
+	}{
+		{
+			"term",
+			`{"term": {"user": {"value": "kimchy", "boost": 2}}}`,
+			`osquery.Term("user", "kimchy").Boost(2)`,
+		},
+		{
+			"match with options",
+			`{"match": {"title": {"query": "go", "operator": "and", "fuzziness": "AUTO"}}}`,
+			`osquery.Match("title", "go").Fuzziness("AUTO").Operator(osquery.OperatorAnd)`,
+		},
+		{
+			"multi match",
+			`{"multi_match": {"query": "go", "fields": ["title", "body"], "type": "phrase"}}`,
+			`osquery.MultiMatch("go").Fields("title", "body").Type(osquery.MatchTypePhrase)`,
+		},
+		{
+			"range",
+			`{"range": {"age": {"gte": 18, "lt": 65.5, "relation": "within"}}}`,
+			`osquery.Range("age").Gte(18).Lt(65.5).Relation(osquery.RangeWithin)`,
+		},
+		{
+			"terms and ids",
+			`{"bool": {"filter": [{"terms": {"tags": ["go", 1, true]}}, {"ids": {"values": ["1"]}}]}}`,
+			`osquery.Bool().Filter(osquery.Terms("tags", "go", 1, true), osquery.IDs("1"))`,
+		},
+		{
+			"empty lists",
+			`{"bool": {"filter": [{"terms": {"tags": []}}, {"ids": {"values": []}}, {"terms_set": {"tags": {"terms": []}}}]}}`,
+			`osquery.Bool().
+	Filter(
+		osquery.Terms("tags", []interface{}{}...),
+		osquery.IDs([]string{}...),
+		osquery.TermsSet("tags", []string{}...),
+	)`,
+		},
+		{
+			"multi match without fields",
+			`{"multi_match": {"query": "go", "fields": []}}`,
+			`osquery.CustomQuery(
+	map[string]interface{}{
+		"multi_match": map[string]interface{}{"fields": []interface{}{}, "query": "go"},
+	},
+)`,
+		},
+		{
+			"knn",
+			`{"knn": {"embedding": {"vector": [0.5, -1, 2e-9], "k": 10, "filter": {"term": {"tag": "go"}}}}}`,
+			`osquery.KNN("embedding", []float64{0.5, -1, 2e-09}).
+	K(10).
+	Filter(map[string]interface{}{"term": map[string]interface{}{"tag": "go"}})`,
+		},
+		{
+			"long bool split over lines",
+			`{"bool": {
+				"must": [{"match_phrase": {"title": "quick brown fox"}}, {"term": {"user": "kimchy"}}],
+				"must_not": [{"exists": {"field": "deleted"}}],
+				"minimum_should_match": 1
+			}}`,
+			`osquery.Bool().
+	Must(
+		osquery.MatchPhrase("title", "quick brown fox"),
+		osquery.Term("user", "kimchy"),
+	).
+	MustNot(osquery.Exists("deleted")).
+	MinimumShouldMatch(1)`,
+		},
+		{
+			"nested",
+			`{"nested": {"path": "comments", "score_mode": "max", "query": {"wildcard": {"comments.author": "ki*"}}}}`,
+			`osquery.Nested("comments", osquery.Wildcard("comments.author", "ki*")).
+	ScoreMode(osquery.ScoreModeMax)`,
+		},
+		{
+			"unsupported query",
+			`{"bool": {"should": [{"geo_distance": {"distance": "1km", "location": [1.5, 2]}}]}}`,
+			`osquery.Bool().
+	Should(
+		osquery.CustomQuery(
+			map[string]interface{}{
+				"geo_distance": map[string]interface{}{"distance": "1km", "location": []interface{}{1.5, 2}},
+			},
+		),
+	)`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, err := GenerateQuery([]byte(test.json))
+			assert.Nil(t, err)
+			assert.Equal(t, test.exp, code)
+		})
+	}
+}
+
+func TestGenerateAggs(t *testing.T) {
+	code, err := GenerateAggs([]byte(`{
+		"by_tag": {
+			"terms": {"field": "tags", "size": 10},
+			"aggs": {"avg_score": {"avg": {"field": "score", "missing": 0}}}
+		},
+		"per_day": {"date_histogram": {"field": "date", "calendar_interval": "day"}},
+		"bounds": {"geo_bounds": {"field": "location"}}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, `[]osquery.Aggregation{
+	osquery.CustomAgg(
+		"bounds",
+		map[string]interface{}{"geo_bounds": map[string]interface{}{"field": "location"}},
+	),
+	osquery.TermsAgg("by_tag", "tags").
+		Size(10).
+		Aggs(osquery.Avg("avg_score", "score").Missing(0)),
+	osquery.DateHistogramAgg("per_day", "date").CalendarInterval("day"),
+}`, code)
+}
+
+func TestGenerateSearchRequest(t *testing.T) {
+	code, err := GenerateSearchRequest([]byte(`{
+		"query": {"match_all": {}},
+		"aggs": {"max_price": {"max": {"field": "price"}}},
+		"size": 10,
+		"timeout": "90s",
+		"sort": [{"date": {"order": "desc", "missing": "_last"}}],
+		"_source": {"includes": ["title"], "excludes": ["body"]},
+		"track_total_hits": true
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, `osquery.Search().
+	Query(osquery.MatchAll()).
+	Aggs(osquery.Max("max_price", "price")).
+	Size(10).
+	Timeout(90*time.Second).
+	Sort(
+		osquery.FieldSort("date").
+			Order(osquery.OrderDesc).
+			Missing(osquery.MissingLast),
+	).
+	SourceIncludes("title").
+	SourceExcludes("body").
+	Custom("track_total_hits", true)`, code)
+}
+
+func TestGenerateEmptyLists(t *testing.T) {
+	// the code generated for empty lists keeps rendering them as such
+	assertJSON(t, `{"terms": {"tags": []}}`, Terms("tags", []interface{}{}...).Map())
+	assertJSON(t, `{"ids": {"values": []}}`, IDs([]string{}...).Map())
+	assertJSON(t, `{"terms_set": {"tags": {"terms": []}}}`, TermsSet("tags", []string{}...).Map())
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := GenerateQuery([]byte(`{"term": `))
+	assert.NotNil(t, err)
+
+	_, err = GenerateAggs([]byte(`{"by_tag": "terms"}`))
+	assert.NotNil(t, err)
+
+	_, err = GenerateSearchRequest([]byte(`{"size": "ten"}`))
+	assert.NotNil(t, err)
+}
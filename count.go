@@ -9,6 +9,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 
 	opensearch "github.com/opensearch-project/opensearch-go/v4"
 	opensearchapi "github.com/opensearch-project/opensearch-go/v4/opensearchapi"
@@ -19,55 +21,125 @@ import (
 // https://opensearch.org/docs/latest/api-reference/count/
 type CountRequest struct {
 	Query Mappable
+
+	indices        []string
+	routing        []string
+	minScore       *float32
+	terminateAfter *int
 }
 
-// Count creates a new count request with the provided query.
+// Count creates a new count request with the provided query. A nil query
+// counts all documents.
 func Count(q Mappable) *CountRequest {
 	return &CountRequest{
 		Query: q,
 	}
 }
 
+// Index sets the indices to count documents in. It takes precedence over the
+// indices of the options provided to Run.
+func (req *CountRequest) Index(indices ...string) *CountRequest {
+	req.indices = indices
+	return req
+}
+
+// Routing sets the routing values used to route the request to specific
+// shards.
+func (req *CountRequest) Routing(routing ...string) *CountRequest {
+	req.routing = routing
+	return req
+}
+
+// MinScore sets the minimum score documents must have to be counted.
+func (req *CountRequest) MinScore(score float32) *CountRequest {
+	req.minScore = &score
+	return req
+}
+
+// TerminateAfter sets the maximum number of documents to count per shard,
+// after which the request terminates early.
+func (req *CountRequest) TerminateAfter(n int) *CountRequest {
+	req.terminateAfter = &n
+	return req
+}
+
 // Map returns a map representation of the request, thus implementing the
 // Mappable interface.
 func (req *CountRequest) Map() map[string]interface{} {
+	if req.Query == nil {
+		return map[string]interface{}{}
+	}
 	return map[string]interface{}{
 		"query": req.Query.Map(),
 	}
 }
 
-// Run executes the request using the provided OpenSearch client. It returns
-// the HTTP response directly for further processing.
+// CountResult is the response of a count request.
+type CountResult struct {
+	Count  int64                        `json:"count"`
+	Shards opensearchapi.ResponseShards `json:"_shards"`
+}
+
+// Run executes the request against the "_count" endpoint using the provided
+// OpenSearch client. Values set on the request itself take precedence
+// over the options' Params.
 func (req *CountRequest) Run(
 	ctx context.Context,
 	client *opensearch.Client,
-	options *Options,
-) (*opensearchapi.SearchResp, error) {
+	options *CountOptions,
+) (*CountResult, error) {
+	if err := validateQuery(req.Query, "query"); err != nil {
+		return nil, fmt.Errorf("invalid count request: %w", err)
+	}
+
 	// Serialize the request body to JSON
 	body, err := json.Marshal(req.Map())
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize request body: %w", err)
 	}
 
-	// Create a Search request, setting size to 0 to avoid fetching documents
-	searchReq := opensearchapi.SearchReq{
+	countReq := opensearchapi.IndicesCountReq{
 		Body: bytes.NewReader(body),
 	}
 
 	// Apply additional options if provided
-	err = ApplyOptions(&searchReq, options)
-	if err != nil {
-		return nil, err
+	options.Apply(&countReq.Indices, &countReq.Header, &countReq.Params)
+
+	if req.indices != nil {
+		countReq.Indices = req.indices
+	}
+	if req.routing != nil {
+		countReq.Params.Routing = req.routing
+	}
+	if req.terminateAfter != nil {
+		countReq.Params.TerminateAfter = req.terminateAfter
 	}
 
-	// Create a variable to hold the response
-	var searchResp opensearchapi.SearchResp
+	var res CountResult
+	if err := execute(ctx, client, countRequest{countReq, req.minScore}, &res); err != nil {
+		return nil, fmt.Errorf("count request failed: %w", err)
+	}
+
+	return &res, nil
+}
 
-	// Execute the search request using the OpenSearch client's Do method
-	if _, err := client.Do(ctx, searchReq, &searchResp); err != nil {
-		return nil, fmt.Errorf("search request failed: %w", err)
+// countRequest wraps a count request of the official client to send a
+// fractional "min_score" parameter, which it only supports as an integer.
+type countRequest struct {
+	opensearchapi.IndicesCountReq
+	minScore *float32
+}
+
+// GetRequest implements the opensearch.Request interface.
+func (r countRequest) GetRequest() (*http.Request, error) {
+	httpReq, err := r.IndicesCountReq.GetRequest()
+	if err != nil || r.minScore == nil {
+		return httpReq, err
 	}
 
-	// Return the parsed response
-	return &searchResp, nil
+	query := httpReq.URL.Query()
+	query.Set("min_score", strconv.FormatFloat(float64(*r.minScore), 'f', -1, 32))
+	httpReq.URL.RawQuery = query.Encode()
+
+	return httpReq, nil
 }
@@ -4,7 +4,13 @@
 
 package osquery
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
 
 func TestCount(t *testing.T) {
 	runMapTests(t, []mapTest{
@@ -17,5 +23,43 @@ func TestCount(t *testing.T) {
 				},
 			},
 		},
+		{
+			"a count request without a query",
+			Count(nil).Index("posts").MinScore(1.5),
+			map[string]interface{}{},
+		},
 	})
 }
+
+func TestCountRun(t *testing.T) {
+	client, requests := newTestClient(t, `{
+		"count": 12345,
+		"_shards": {"total": 2, "successful": 2, "skipped": 0, "failed": 0}
+	}`)
+
+	res, err := Count(Term("tag", "tech")).
+		Index("posts", "comments").
+		Routing("user1").
+		MinScore(0.5).
+		TerminateAfter(100000).
+		Run(context.Background(), client, &CountOptions{
+			Indices: []string{"ignored"},
+			Params:  &opensearchapi.IndicesCountParams{Preference: "_local"},
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12345), res.Count)
+	assert.Equal(t, 2, res.Shards.Successful)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/posts,comments/_count", (*requests)[0].path)
+	assert.Equal(t, "min_score=0.5&preference=_local&routing=user1&terminate_after=100000", (*requests)[0].query)
+	assert.Equal(t, `{"query":{"term":{"tag":{"value":"tech"}}}}`, (*requests)[0].body)
+}
+
+func TestCountRunError(t *testing.T) {
+	client, _ := newTestClient(t, `{"error": {"root_cause": [], "type": "index_not_found_exception", "reason": "no such index [posts]"}, "status": 404}`)
+
+	res, err := Count(MatchAll()).Index("posts").Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.True(t, res == nil)
+}
@@ -6,6 +6,7 @@ package osquery
 
 import (
 	context "context"
+	"encoding/json"
 
 	opensearch "github.com/opensearch-project/opensearch-go/v4"
 	opensearchapi "github.com/opensearch-project/opensearch-go/v4/opensearchapi"
@@ -32,11 +33,12 @@ func (m *CustomQueryMap) Map() map[string]interface{} {
 
 // Run executes the custom query using the provided OpenSearch client. Zero
 // or more search options can be provided as well. It returns the standard
-// Response type of the official Go client.
+// Response type of the official Go client; use RunCustomInto to decode the
+// hits into documents of a specific type.
 func (m *CustomQueryMap) Run(
 	ctx context.Context,
 	api *opensearch.Client,
-	options *Options,
+	options *SearchOptions,
 ) (res *opensearchapi.SearchResp, err error) {
 	return Search().Query(m).Run(ctx, api, options)
 }
@@ -68,3 +70,13 @@ func (agg *CustomAggMap) Name() string {
 func (agg *CustomAggMap) Map() map[string]interface{} {
 	return agg.agg
 }
+
+// Result returns the undecoded result of the custom aggregation from the
+// provided aggregation results.
+func (agg *CustomAggMap) Result(aggs AggregationResults) (json.RawMessage, error) {
+	var res json.RawMessage
+	if err := aggs.decode(agg.name, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	opensearch "github.com/opensearch-project/opensearch-go/v4"
@@ -16,10 +17,15 @@ import (
 
 // DeleteRequest represents a request to OpenSearch's Delete By Query API,
 // described in
-// https://opensearch.org/docs/latest/search-plugins/sql/sql/delete/
+// https://opensearch.org/docs/latest/api-reference/document-apis/delete-by-query/
 type DeleteRequest struct {
-	index []string
-	query Mappable
+	index             []string
+	query             Mappable
+	conflicts         Conflicts
+	slices            interface{}
+	requestsPerSecond *int
+	maxDocs           *int
+	refresh           *bool
 }
 
 // Delete creates a new DeleteRequest object, to be filled via method chaining.
@@ -27,7 +33,8 @@ func Delete() *DeleteRequest {
 	return &DeleteRequest{}
 }
 
-// Index sets the index names for the request
+// Index sets the index names for the request. They take precedence over the
+// indices of the options provided to Run.
 func (req *DeleteRequest) Index(index ...string) *DeleteRequest {
 	req.index = index
 	return req
@@ -39,14 +46,93 @@ func (req *DeleteRequest) Query(q Mappable) *DeleteRequest {
 	return req
 }
 
-// Run executes the request using the provided OpenSearch client.
+// Conflicts sets what to do when a document changed while being deleted. The
+// default is to abort the request.
+func (req *DeleteRequest) Conflicts(c Conflicts) *DeleteRequest {
+	req.conflicts = c
+	return req
+}
+
+// Slices sets the number of slices the request is divided into.
+func (req *DeleteRequest) Slices(n int) *DeleteRequest {
+	req.slices = n
+	return req
+}
+
+// AutoSlices lets OpenSearch choose the number of slices the request is
+// divided into.
+func (req *DeleteRequest) AutoSlices() *DeleteRequest {
+	req.slices = "auto"
+	return req
+}
+
+// RequestsPerSecond throttles the request to the provided number of requests
+// per second. A value of -1 disables throttling.
+func (req *DeleteRequest) RequestsPerSecond(n int) *DeleteRequest {
+	req.requestsPerSecond = &n
+	return req
+}
+
+// MaxDocs sets the maximum number of documents to delete.
+func (req *DeleteRequest) MaxDocs(n int) *DeleteRequest {
+	req.maxDocs = &n
+	return req
+}
+
+// Refresh sets whether the affected shards are refreshed once the request
+// completes.
+func (req *DeleteRequest) Refresh(b bool) *DeleteRequest {
+	req.refresh = &b
+	return req
+}
+
+// Map returns a map representation of the request body, thus implementing the
+// Mappable interface.
+func (req *DeleteRequest) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if req.query != nil {
+		m["query"] = req.query.Map()
+	}
+	return m
+}
+
+// Run executes the request using the provided OpenSearch client, waiting for
+// it to complete.
 func (req *DeleteRequest) Run(
 	ctx context.Context,
 	client *opensearch.Client,
-	options *Options,
+	options *DeleteOptions,
 ) (*opensearchapi.DocumentDeleteByQueryResp, error) {
+	return req.run(ctx, client, options, true)
+}
+
+// RunAsync starts the request using the provided OpenSearch client without
+// waiting for it to complete, and returns a handle on the task running it.
+func (req *DeleteRequest) RunAsync(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *DeleteOptions,
+) (*Task, error) {
+	res, err := req.run(ctx, client, options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Task{ID: res.Task, api: "delete_by_query"}, nil
+}
+
+func (req *DeleteRequest) run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *DeleteOptions,
+	wait bool,
+) (*opensearchapi.DocumentDeleteByQueryResp, error) {
+	if err := validateQuery(req.query, "query"); err != nil {
+		return nil, fmt.Errorf("invalid delete by query request: %w", err)
+	}
+
 	// Serialize the request body to JSON
-	body, err := json.Marshal(req.query.Map())
+	body, err := json.Marshal(req.Map())
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize request body: %w", err)
 	}
@@ -57,17 +143,55 @@ func (req *DeleteRequest) Run(
 	}
 
 	// Apply any additional options to modify the DeleteReq, such as context or index
-	err = ApplyOptions(&deleteReq, options)
-	if err != nil {
-		return nil, err
+	options.Apply(&deleteReq.Indices, &deleteReq.Header, &deleteReq.Params)
+
+	if req.index != nil {
+		deleteReq.Indices = req.index
+	}
+	if len(deleteReq.Indices) == 0 {
+		return nil, errors.New("delete by query requires at least one index")
+	}
+
+	params := &deleteReq.Params
+	if req.conflicts != "" {
+		params.Conflicts = string(req.conflicts)
+	}
+	if req.slices != nil {
+		params.Slices = req.slices
+	}
+	if req.requestsPerSecond != nil {
+		params.RequestsPerSecond = req.requestsPerSecond
+	}
+	if req.maxDocs != nil {
+		params.MaxDocs = req.maxDocs
+	}
+	if req.refresh != nil {
+		params.Refresh = req.refresh
+	}
+	if !wait {
+		params.WaitForCompletion = &wait
 	}
 
 	var deleteResp opensearchapi.DocumentDeleteByQueryResp
 
 	// Execute the delete request using the OpenSearch client's Do method
-	if _, err := client.Do(ctx, deleteReq, &deleteResp); err != nil {
+	if err := execute(ctx, client, deleteReq, &deleteResp); err != nil {
 		return nil, fmt.Errorf("delete request failed: %w", err)
 	}
 
 	return &deleteResp, nil
 }
+
+// Conflicts is an enumeration type representing supported values for the
+// "conflicts" parameter of by-query requests, which determines what happens
+// when a document changes while being processed.
+type Conflicts string
+
+const (
+	// ConflictsAbort aborts the request on the first version conflict (the
+	// default).
+	ConflictsAbort Conflicts = "abort"
+
+	// ConflictsProceed counts version conflicts and continues.
+	ConflictsProceed Conflicts = "proceed"
+)
@@ -0,0 +1,133 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+func TestDelete(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"a simple delete request",
+			Delete().Index("posts").Query(Term("tag", "spam")),
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"term": map[string]interface{}{
+						"tag": map[string]interface{}{
+							"value": "spam",
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestDeleteRun(t *testing.T) {
+	client, requests := newTestClient(t, `{
+		"took": 147,
+		"timed_out": false,
+		"total": 119,
+		"deleted": 119,
+		"batches": 1,
+		"version_conflicts": 0,
+		"failures": []
+	}`)
+
+	res, err := Delete().
+		Index("posts").
+		Query(Term("tag", "spam")).
+		Conflicts(ConflictsProceed).
+		AutoSlices().
+		RequestsPerSecond(500).
+		MaxDocs(1000).
+		Refresh(true).
+		Run(context.Background(), client, &DeleteOptions{
+			Indices: []string{"ignored"},
+			Params:  &opensearchapi.DocumentDeleteByQueryParams{Routing: []string{"user1"}},
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, 119, res.Deleted)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/posts/_delete_by_query", (*requests)[0].path)
+	assert.Equal(t,
+		"conflicts=proceed&max_docs=1000&refresh=true&requests_per_second=500&routing=user1&slices=auto",
+		(*requests)[0].query,
+	)
+	assert.Equal(t, `{"query":{"term":{"tag":{"value":"spam"}}}}`, (*requests)[0].body)
+}
+
+func TestDeleteRunWithoutIndex(t *testing.T) {
+	client, requests := newTestClient(t)
+
+	_, err := Delete().Query(MatchAll()).Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(*requests))
+}
+
+func TestDeleteRunAsync(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"task": "oTUltX4IQMOUUVeiohTt8A:12345"}`,
+		`{"nodes": {}}`,
+		`{"completed": false, "task": {"node": "oTUltX4IQMOUUVeiohTt8A", "id": 12345, "action": "indices:data/write/delete/byquery", "status": {"total": 100, "deleted": 40}}}`,
+		`{"completed": true, "task": {"node": "oTUltX4IQMOUUVeiohTt8A", "id": 12345, "status": {"total": 100, "deleted": 100}}, "response": {"deleted": 100}}`,
+		`{"nodes": {}}`,
+	)
+
+	ctx := context.Background()
+	task, err := Delete().
+		Index("posts").
+		Query(MatchAll()).
+		Slices(5).
+		RunAsync(ctx, client, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "oTUltX4IQMOUUVeiohTt8A:12345", task.ID)
+	assert.Equal(t, "slices=5&wait_for_completion=false", (*requests)[0].query)
+
+	assert.Nil(t, task.Rethrottle(ctx, client, -1))
+	assert.Equal(t, "/_delete_by_query/oTUltX4IQMOUUVeiohTt8A:12345/_rethrottle", (*requests)[1].path)
+	assert.Equal(t, "requests_per_second=-1", (*requests)[1].query)
+
+	status, err := task.Status(ctx, client)
+	assert.Nil(t, err)
+	assert.False(t, status.Completed)
+	assert.Equal(t, int64(40), status.Task.Status.Deleted)
+	assert.Equal(t, "/_tasks/oTUltX4IQMOUUVeiohTt8A:12345", (*requests)[2].path)
+
+	status, err = task.Wait(ctx, client, time.Millisecond)
+	assert.Nil(t, err)
+	assert.True(t, status.Completed)
+	assert.Equal(t, `{"deleted": 100}`, string(status.Response))
+
+	assert.Nil(t, task.Cancel(ctx, client))
+	assert.Equal(t, "POST", (*requests)[4].method)
+	assert.Equal(t, "/_tasks/oTUltX4IQMOUUVeiohTt8A:12345/_cancel", (*requests)[4].path)
+}
+
+func TestTaskNodeFailures(t *testing.T) {
+	client, _ := newTestClient(t, `{
+		"nodes": {},
+		"node_failures": [{"type": "failed_node_exception", "reason": "Failed node [abc]"}]
+	}`)
+
+	task := &Task{ID: "abc:1"}
+	assert.NotNil(t, task.Cancel(context.Background(), client))
+	assert.NotNil(t, task.Rethrottle(context.Background(), client, 10))
+}
+
+func TestTaskWaitNonPositiveInterval(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"completed": true, "task": {"node": "abc", "id": 1, "status": {"deleted": 1}}, "response": {"deleted": 1}}`,
+	)
+
+	task := &Task{ID: "abc:1"}
+	status, err := task.Wait(context.Background(), client, 0)
+	assert.Nil(t, err)
+	assert.True(t, status.Completed)
+	assert.Equal(t, 1, len(*requests))
+}
@@ -5,6 +5,10 @@
 package osquery
 
 import (
+	"fmt"
+	"io"
+	"sort"
+
 	"github.com/fatih/structs"
 )
 
@@ -25,6 +29,87 @@ func (q *QueryHighlight) Map() map[string]interface{} {
 	return results
 }
 
+// WriteJSON writes the JSON encoding of the highlight to w, thus implementing
+// the JSONWriter interface.
+func (q *QueryHighlight) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *QueryHighlight) encodeJSON(e *encoder) {
+	str := func(key, s string) {
+		if s != "" {
+			e.key(key)
+			e.str(s)
+		}
+	}
+	num := func(key string, i uint16) {
+		if i != 0 {
+			e.key(key)
+			e.uint(uint64(i))
+		}
+	}
+	flag := func(key string, b *bool) {
+		if b != nil {
+			e.key(key)
+			e.boolean(*b)
+		}
+	}
+	list := func(key string, l []string) {
+		if l != nil {
+			e.key(key)
+			e.strings(l)
+		}
+	}
+	enum := func(key string, isSet bool, s fmt.Stringer) {
+		if isSet {
+			e.key(key)
+			e.str(s.String())
+		}
+	}
+
+	p := &q.params
+	e.openObject()
+	str("boundary_chars", p.BoundaryChars)
+	num("boundary_max_scan", p.BoundaryMaxScan)
+	enum("boundary_scanner", p.BoundaryScanner != 0, p.BoundaryScanner)
+	str("boundary_scanner_locale", p.BoundaryScannerLocale)
+	enum("encoder", p.Encoder != 0, p.Encoder)
+	if len(q.fields) > 0 {
+		names := make([]string, 0, len(q.fields))
+		for name := range q.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		e.key("fields")
+		e.openObject()
+		for _, name := range names {
+			e.key(name)
+			q.fields[name].encodeJSON(e)
+		}
+		e.closeObject()
+	}
+	flag("force_source", p.ForceSource)
+	num("fragment_offset", p.FragmentOffset)
+	num("fragment_size", p.FragmentSize)
+	enum("fragmenter", p.Fragmenter != 0, p.Fragmenter)
+	list("matched_fields", p.MatchedFields)
+	num("no_match_size", p.NoMatchSize)
+	num("number_of_fragments", p.NumberOfFragments)
+	enum("order", p.Order != 0, p.Order)
+	num("phrase_limit", p.PhraseLimit)
+	list("post_tags", p.PostTags)
+	list("pre_tags", p.PreTags)
+	if q.highlightQuery != nil {
+		e.key("query")
+		e.mappable(q.highlightQuery)
+	}
+	flag("require_field_match", p.RequireFieldMatch)
+	enum("tags_schema", p.TagsSchema != 0, p.TagsSchema)
+	enum("type", p.Type != 0, p.Type)
+	e.closeObject()
+}
+
 type QueryHighlight struct {
 	highlightQuery Mappable                   `structs:"highlight_query,omitempty"`
 	fields         map[string]*QueryHighlight `structs:"fields"`
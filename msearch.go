@@ -0,0 +1,219 @@
+package osquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// MultiSearchRequest represents a request to the multi search API, which
+// executes several search requests in a single round trip, as described in
+// https://opensearch.org/docs/latest/api-reference/multi-search/
+type MultiSearchRequest struct {
+	searches []multiSearchItem
+}
+
+type multiSearchItem struct {
+	req     *SearchRequest
+	options *SearchOptions
+}
+
+// MultiSearch creates a new MultiSearchRequest object, to be filled via method
+// chaining.
+func MultiSearch() *MultiSearchRequest {
+	return &MultiSearchRequest{}
+}
+
+// Add appends a search request to the multi search. The indices of the
+// provided options are the indices searched by the request, and its Params,
+// if any, provide the parameters supported by the multi search API for each
+// request: allow_no_indices, expand_wildcards,
+// ignore_unavailable, preference, request_cache, routing, search_type,
+// allow_partial_search_results and ccs_minimize_roundtrips. Options may be
+// nil.
+func (req *MultiSearchRequest) Add(search *SearchRequest, options *SearchOptions) *MultiSearchRequest {
+	req.searches = append(req.searches, multiSearchItem{
+		req:     search,
+		options: options,
+	})
+	return req
+}
+
+// Len returns the number of search requests of the multi search.
+func (req *MultiSearchRequest) Len() int {
+	return len(req.searches)
+}
+
+// Body returns the NDJSON body of the multi search, made of a header line and
+// a body line for each search request.
+func (req *MultiSearchRequest) Body() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	for i, item := range req.searches {
+		if err := item.req.Validate(); err != nil {
+			return nil, fmt.Errorf("search %d: invalid search request: %w", i, err)
+		}
+		header, err := item.header()
+		if err != nil {
+			return nil, fmt.Errorf("search %d: %w", i, err)
+		}
+		if err := enc.Encode(header); err != nil {
+			return nil, fmt.Errorf("search %d: failed to serialize header: %w", i, err)
+		}
+		if err := item.req.WriteJSON(&buf); err != nil {
+			return nil, fmt.Errorf("search %d: failed to serialize request body: %w", i, err)
+		}
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// header returns the header line of a search request.
+func (item multiSearchItem) header() (map[string]interface{}, error) {
+	header := make(map[string]interface{})
+	if item.options == nil {
+		return header, nil
+	}
+	if item.options.Header != nil {
+		return nil, errors.New("HTTP headers cannot be set for a single search of a multi search")
+	}
+
+	if len(item.options.Indices) > 0 {
+		header["index"] = item.options.Indices
+	}
+
+	params := item.options.Params
+	if params == nil {
+		return header, nil
+	}
+
+	if params.AllowNoIndices != nil {
+		header["allow_no_indices"] = *params.AllowNoIndices
+	}
+	if params.ExpandWildcards != "" {
+		header["expand_wildcards"] = params.ExpandWildcards
+	}
+	if params.IgnoreUnavailable != nil {
+		header["ignore_unavailable"] = *params.IgnoreUnavailable
+	}
+	if params.Preference != "" {
+		header["preference"] = params.Preference
+	}
+	if params.RequestCache != nil {
+		header["request_cache"] = *params.RequestCache
+	}
+	if len(params.Routing) > 0 {
+		header["routing"] = strings.Join(params.Routing, ",")
+	}
+	if params.SearchType != "" {
+		header["search_type"] = params.SearchType
+	}
+	if params.AllowPartialSearchResults != nil {
+		header["allow_partial_search_results"] = *params.AllowPartialSearchResults
+	}
+	if params.CcsMinimizeRoundtrips != nil {
+		header["ccs_minimize_roundtrips"] = *params.CcsMinimizeRoundtrips
+	}
+
+	return header, nil
+}
+
+// Run executes the multi search using the OpenSearch client. The options apply
+// to the multi search request as a whole. Results are returned in the order the
+// search requests were added, with the source of their hits left undecoded.
+// The returned error is only set if the multi search itself failed; errors of
+// single searches are reported by their item.
+func (req *MultiSearchRequest) Run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *MultiSearchOptions,
+) ([]MultiSearchItem[json.RawMessage], error) {
+	return RunMultiInto[json.RawMessage](ctx, req, client, options)
+}
+
+// MultiSearchItem is the result of a single search of a multi search. Err is
+// set if the search failed, in which case Result is nil.
+type MultiSearchItem[T any] struct {
+	Status int
+	Result *SearchResult[T]
+	Err    error
+}
+
+// RunMultiInto executes the multi search like MultiSearchRequest.Run, but
+// decodes the hits of every search into documents of type T.
+func RunMultiInto[T any](
+	ctx context.Context,
+	req *MultiSearchRequest,
+	client *opensearch.Client,
+	options *MultiSearchOptions,
+) ([]MultiSearchItem[T], error) {
+	body, err := req.Body()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize request body: %w", err)
+	}
+
+	msearchReq := opensearchapi.MSearchReq{
+		Body: bytes.NewReader(body),
+	}
+
+	options.Apply(&msearchReq.Indices, &msearchReq.Header, &msearchReq.Params)
+
+	var res struct {
+		Responses []json.RawMessage `json:"responses"`
+	}
+	if err := execute(ctx, client, msearchReq, &res); err != nil {
+		return nil, fmt.Errorf("multi search request failed: %w", err)
+	}
+	if len(res.Responses) != len(req.searches) {
+		return nil, fmt.Errorf(
+			"multi search returned %d responses for %d searches",
+			len(res.Responses), len(req.searches),
+		)
+	}
+
+	items := make([]MultiSearchItem[T], len(res.Responses))
+	for i, raw := range res.Responses {
+		items[i] = decodeMultiSearchItem[T](raw)
+	}
+
+	return items, nil
+}
+
+// decodeMultiSearchItem decodes a single response of a multi search.
+func decodeMultiSearchItem[T any](raw json.RawMessage) (item MultiSearchItem[T]) {
+	var head struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		item.Err = err
+		return item
+	}
+	item.Status = head.Status
+
+	if head.Error != nil {
+		item.Err = opensearch.ParseError(&opensearch.Response{
+			StatusCode: head.Status,
+			Body:       io.NopCloser(bytes.NewReader(raw)),
+		})
+		return item
+	}
+
+	var res SearchResult[T]
+	if err := json.Unmarshal(raw, &res); err != nil {
+		item.Err = err
+		return item
+	}
+	item.Result = &res
+
+	return item
+}
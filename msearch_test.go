@@ -0,0 +1,87 @@
+package osquery
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+func TestMultiSearchBody(t *testing.T) {
+	cache := true
+	body, err := MultiSearch().
+		Add(Query(Term("title", "Go")).Size(1), &SearchOptions{
+			Indices: []string{"posts"},
+			Params: &opensearchapi.SearchParams{
+				Routing:      []string{"user1", "user2"},
+				Preference:   "_local",
+				RequestCache: &cache,
+			},
+		}).
+		Add(Aggregate(Max("max_views", "views")).Size(0), nil).
+		Body()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"index":["posts"],"preference":"_local","request_cache":true,"routing":"user1,user2"}
+{"query":{"term":{"title":{"value":"Go"}}},"size":1}
+{}
+{"aggs":{"max_views":{"max":{"field":"views"}}},"size":0}
+`, string(body))
+
+	_, err = MultiSearch().
+		Add(Search(), &SearchOptions{Header: http.Header{"X-Opaque-Id": []string{"1"}}}).
+		Body()
+	assert.NotNil(t, err)
+}
+
+func TestMultiSearchRun(t *testing.T) {
+	client, requests := newTestClient(t, `{"took": 5, "responses": [
+		{"took": 2, "hits": {"total": {"value": 1, "relation": "eq"}, "hits": [
+			{"_index": "posts", "_id": "1", "_source": {"title": "Go and Stuff", "views": 10}}
+		]}, "status": 200},
+		{"error": {"root_cause": [], "type": "index_not_found_exception", "reason": "no such index [missing]"}, "status": 404},
+		{"took": 1, "hits": {"hits": []}, "aggregations": {"max_views": {"value": 10}}, "status": 200}
+	]}`)
+
+	req := MultiSearch().
+		Add(Query(Term("title", "Go")), &SearchOptions{Indices: []string{"posts"}}).
+		Add(Search(), &SearchOptions{Indices: []string{"missing"}}).
+		Add(Aggregate(Max("max_views", "views")), nil)
+	assert.Equal(t, 3, req.Len())
+
+	maxSearches := 4
+	items, err := RunMultiInto[testDoc](context.Background(), req, client, &MultiSearchOptions{
+		Params: &opensearchapi.MSearchParams{MaxConcurrentSearches: &maxSearches},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/_msearch", (*requests)[0].path)
+	assert.Equal(t, "max_concurrent_searches=4", (*requests)[0].query)
+
+	assert.Equal(t, 3, len(items))
+	assert.Nil(t, items[0].Err)
+	assert.Equal(t, 200, items[0].Status)
+	assert.DeepEqual(t, []testDoc{{Title: "Go and Stuff", Views: 10}}, items[0].Result.Documents())
+
+	assert.Equal(t, 404, items[1].Status)
+	assert.True(t, items[1].Result == nil)
+	osErr, ok := items[1].Err.(*opensearch.StructError)
+	assert.True(t, ok)
+	assert.Equal(t, "index_not_found_exception", osErr.Err.Type)
+
+	assert.Nil(t, items[2].Err)
+	maxViews, err := Max("max_views", "views").Result(items[2].Result.Aggregations)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, *maxViews.Value)
+}
+
+func TestMultiSearchRunError(t *testing.T) {
+	client, _ := newTestClient(t, `{"error": {"root_cause": [], "type": "parse_exception", "reason": "bad body"}, "status": 400}`)
+
+	items, err := MultiSearch().Add(Search(), nil).Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.True(t, items == nil)
+}
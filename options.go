@@ -1,58 +1,78 @@
 package osquery
 
 import (
-	"fmt"
 	"net/http"
 
 	opensearchapi "github.com/opensearch-project/opensearch-go/v4/opensearchapi"
 )
 
-type Options struct {
+// Options holds additional options for a request: the indices it targets,
+// HTTP headers, and the query parameters of the official client for that
+// request type. The type parameter ties the options to a request type, so
+// passing parameters meant for a different request is a compile-time error.
+// Use the aliases below, e.g. &osquery.SearchOptions{...} for search
+// requests.
+type Options[P any] struct {
 	Indices []string
 	Header  http.Header
-	Params  interface{}
+	Params  *P
 }
 
-// ApplyOptions applies additional options to the request if provided.
-// ApplyOptions applies additional options to the request if provided.
-func ApplyOptions(req interface{}, options *Options) error {
-	if options == nil {
-		return nil
+type (
+	// SearchOptions are the options of search requests, including custom
+	// queries, the search requests of a multi search, and the point in time
+	// and scroll iterators.
+	SearchOptions = Options[opensearchapi.SearchParams]
+
+	// CountOptions are the options of count requests.
+	CountOptions = Options[opensearchapi.IndicesCountParams]
+
+	// DeleteOptions are the options of delete by query requests.
+	DeleteOptions = Options[opensearchapi.DocumentDeleteByQueryParams]
+
+	// UpdateByQueryOptions are the options of update by query requests.
+	UpdateByQueryOptions = Options[opensearchapi.UpdateByQueryParams]
+
+	// ReindexOptions are the options of reindex requests. Indices are ignored,
+	// as the indices of a reindex request are part of its body.
+	ReindexOptions = Options[opensearchapi.ReindexParams]
+
+	// MultiSearchOptions are the options of a multi search request as a whole.
+	MultiSearchOptions = Options[opensearchapi.MSearchParams]
+)
+
+// Apply applies the options to the fields of a request of the official
+// client. Indices and parameters replace those of the request if set, while
+// headers are merged into the request's headers, replacing the values of keys
+// present in both. A nil indices pointer leaves the request's indices
+// untouched. It is safe to call Apply on nil options.
+func (o *Options[P]) Apply(indices *[]string, header *http.Header, params *P) {
+	if o == nil {
+		return
 	}
 
-	switch r := req.(type) {
-	case *opensearchapi.SearchReq:
-		if options.Indices != nil {
-			r.Indices = options.Indices // Correctly assigning to the field
-		}
-		if options.Header != nil {
-			r.Header = options.Header
-		}
-		if options.Params != nil {
-			params, ok := options.Params.(*opensearchapi.SearchParams)
-			if !ok {
-				return fmt.Errorf("invalid type for SearchParams")
-			}
-			r.Params = *params
-		}
-	case *opensearchapi.DocumentDeleteByQueryReq:
-		if options.Indices != nil {
-			r.Indices = options.Indices
-		}
-		if options.Header != nil {
-			r.Header = options.Header
-		}
-		if options.Params != nil {
-			params, ok := options.Params.(*opensearchapi.DocumentDeleteByQueryParams)
-			if !ok {
-				return fmt.Errorf("invalid type for DocumentDeleteByQueryParams")
-			}
-			r.Params = *params
-		}
-	// Add more cases for other request types as needed
-	default:
-		return fmt.Errorf("unsupported request type: %T", req)
+	if o.Indices != nil && indices != nil {
+		*indices = o.Indices
+	}
+	*header = mergeHeaders(*header, o.Header)
+	if o.Params != nil {
+		*params = *o.Params
+	}
+}
+
+// mergeHeaders returns a copy of dst with the values of src added to it. Keys
+// present in both take the values of src.
+func mergeHeaders(dst, src http.Header) http.Header {
+	if len(src) == 0 {
+		return dst
 	}
 
-	return nil
+	merged := dst.Clone()
+	if merged == nil {
+		merged = make(http.Header, len(src))
+	}
+	for key, values := range src {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return merged
 }
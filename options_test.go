@@ -0,0 +1,49 @@
+package osquery
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+func TestOptionsApply(t *testing.T) {
+	req := opensearchapi.SearchReq{
+		Indices: []string{"posts"},
+		Header: http.Header{
+			"Accept":      []string{"application/json"},
+			"X-Opaque-Id": []string{"old"},
+		},
+		Params: opensearchapi.SearchParams{Preference: "_primary"},
+	}
+
+	var nilOptions *SearchOptions
+	nilOptions.Apply(&req.Indices, &req.Header, &req.Params)
+	assert.DeepEqual(t, []string{"posts"}, req.Indices)
+	assert.Equal(t, "_primary", req.Params.Preference)
+
+	header := req.Header
+	options := &SearchOptions{
+		Indices: []string{"comments"},
+		Header:  http.Header{"x-opaque-id": []string{"new"}},
+		Params:  &opensearchapi.SearchParams{Preference: "_local"},
+	}
+	options.Apply(&req.Indices, &req.Header, &req.Params)
+	assert.DeepEqual(t, []string{"comments"}, req.Indices)
+	assert.DeepEqual(t, http.Header{
+		"Accept":      []string{"application/json"},
+		"X-Opaque-Id": []string{"new"},
+	}, req.Header)
+	assert.Equal(t, "_local", req.Params.Preference)
+
+	// the original headers must not be modified
+	assert.Equal(t, "old", header.Get("X-Opaque-Id"))
+
+	var reindexReq opensearchapi.ReindexReq
+	(&ReindexOptions{
+		Indices: []string{"ignored"},
+		Header:  http.Header{"X-Opaque-Id": []string{"1"}},
+	}).Apply(nil, &reindexReq.Header, &reindexReq.Params)
+	assert.Equal(t, "1", reindexReq.Header.Get("X-Opaque-Id"))
+}
@@ -89,18 +89,20 @@
 //
 //
 // * osquery currently supports version 7 of the OpenSearch Go client.
-// * The library cannot currently generate "short queries". For example,
+// * By default, the library generates the long form of queries. For example,
 //  whereas OpenSearch can accept this:
 //
 //     { "query": { "term": { "user": "Kimchy" } } }
 //
-// The library will always generate this:
+// The library generates this:
 //
 //     { "query": { "term": { "user": { "value": "Kimchy" } } } }
 //
 // This is also true for queries such as "bool", where fields like "must" can
-// either receive one query object, or an array of query objects. `osquery` will
-// generate an array even if there's only one query object.
+// either receive one query object, or an array of query objects. `osquery`
+// generates an array even if there's only one query object. The short forms
+// can be generated with ShortMap and MarshalShort, or by calling ShortForm on
+// a SearchRequest.
 // Modified by DefenseStation on 2024-06-06
 // Changes: Updated ElasticSearch client to OpenSearch client, changed package name to 'osquery',
 // updated references to OpenSearch documentation, and modified examples accordingly.
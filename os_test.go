@@ -6,10 +6,14 @@ package osquery
 
 import (
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/jgroeneveld/trial/assert"
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
 )
 
 type mapTest struct {
@@ -61,3 +65,56 @@ func runJSONTests(t *testing.T, tests []jsonTest) {
 		})
 	}
 }
+
+type recordedRequest struct {
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+// newTestClient returns an OpenSearch client connected to a test server that
+// replies to every request with the next of the provided response bodies.
+// Bodies shaped like OpenSearch errors ({"error":...,"status":N}) are sent with
+// their status code. The requests received by the server are recorded into the
+// returned slice.
+func newTestClient(t *testing.T, responses ...string) (*opensearch.Client, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.RawQuery,
+			body:   string(body),
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) > len(responses) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"unexpected request","status":500}`))
+			return
+		}
+		res := responses[len(requests)-1]
+		var errRes struct {
+			Error  interface{} `json:"error"`
+			Status int         `json:"status"`
+		}
+		if json.Unmarshal([]byte(res), &errRes) == nil && errRes.Error != nil && errRes.Status >= 400 {
+			w.WriteHeader(errRes.Status)
+		}
+		_, _ = w.Write([]byte(res))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := opensearch.NewClient(opensearch.Config{
+		Addresses: []string{srv.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed creating client: %s", err)
+	}
+
+	return client, &requests
+}
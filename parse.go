@@ -0,0 +1,1407 @@
+package osquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQuery parses the JSON representation of a query, as found in the
+// "query" section of a search request, into the query types of the library,
+// so that it can be modified using the builder API. Both the short and long
+// forms of field-level queries are accepted, e.g. {"term": {"tag": "go"}} and
+// {"term": {"tag": {"value": "go"}}}.
+//
+// Query types that are not supported by the library, or that use parameters
+// the library's types cannot represent, are returned as *CustomQueryMap values
+// holding the original JSON, so that no information is lost. Sub-queries of
+// compound queries are parsed independently: a bool query may be returned as a
+// *BoolQuery holding a mix of concrete and custom queries.
+func ParseQuery(data []byte) (Mappable, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return parseQuery(v, "query")
+}
+
+// ParseSearchRequest parses the JSON body of a search request into a
+// SearchRequest, parsing its queries as ParseQuery does and its aggregations
+// into the aggregation types of the library. Aggregation types that are not
+// supported by the library are returned as *CustomAggMap values. Top-level
+// fields that SearchRequest does not support, such as "track_total_hits" or
+// "collapse", timeouts that are not whole seconds or not valid durations, and
+// point in time settings the request cannot represent, are kept as custom
+// fields of the request, as set by its Custom method. Durations may use the
+// time units of OpenSearch, such as "1d".
+func ParseSearchRequest(data []byte) (*SearchRequest, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("search request: expected an object, got %s", jsonType(v))
+	}
+
+	req := Search()
+	for _, key := range sortedKeys(m) {
+		if err := parseSearchField(req, key, m[key]); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// parseSearchField parses a top-level field of a search request into req.
+func parseSearchField(req *SearchRequest, key string, v interface{}) error {
+	switch key {
+	case "query", "post_filter":
+		q, err := parseQuery(v, key)
+		if err != nil {
+			return err
+		}
+		if key == "query" {
+			req.Query(q)
+		} else {
+			req.PostFilter(q)
+		}
+	case "aggs", "aggregations":
+		aggs, err := parseAggs(v, key)
+		if err != nil {
+			return err
+		}
+		req.Aggs(aggs...)
+	case "size", "from":
+		n, ok := toUint(v, math.MaxUint64)
+		if !ok {
+			return fmt.Errorf("%s: expected a non-negative integer, got %s", key, jsonType(v))
+		}
+		if key == "size" {
+			req.Size(n)
+		} else {
+			req.From(n)
+		}
+	case "explain":
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%s: expected a boolean, got %s", key, jsonType(v))
+		}
+		req.Explain(b)
+	case "timeout":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a duration string, got %s", key, jsonType(v))
+		}
+		dur, ok := parseDuration(s)
+		if !ok || dur%time.Second != 0 {
+			// Timeout only supports whole seconds
+			req.Custom(key, v)
+			return nil
+		}
+		req.Timeout(dur)
+	case "search_after":
+		values, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %s", key, jsonType(v))
+		}
+		req.SearchAfter(values...)
+	case "sort":
+		opts, err := parseSort(v, key)
+		if err != nil {
+			return err
+		}
+		req.Sort(opts...)
+	case "_source":
+		return parseSource(req, v, key)
+	case "highlight":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", key, jsonType(v))
+		}
+		req.Highlight(CustomQuery(m))
+	case "pit":
+		return parsePIT(req, v, key)
+	case "script_fields":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", key, jsonType(v))
+		}
+		for _, name := range sortedKeys(m) {
+			script, err := parseScriptField(name, m[name], key+"."+name)
+			if err != nil {
+				return err
+			}
+			req.ScriptFields(script)
+		}
+	default:
+		req.Custom(key, v)
+	}
+	return nil
+}
+
+// parseSort parses the "sort" section of a search request. Sort options that
+// FieldSortOption cannot represent are returned as custom maps.
+func parseSort(v interface{}, path string) ([]SortOption, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		list = []interface{}{v}
+	}
+
+	opts := make([]SortOption, len(list))
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch item := item.(type) {
+		case string:
+			opts[i] = FieldSort(item)
+		case map[string]interface{}:
+			if len(item) != 1 {
+				return nil, fmt.Errorf("%s: expected a single field, got %d", itemPath, len(item))
+			}
+			opt, err := parseFieldSort(item, itemPath)
+			if err != nil {
+				return nil, err
+			}
+			if opt == nil {
+				opts[i] = CustomQuery(item)
+			} else {
+				opts[i] = opt
+			}
+		default:
+			return nil, fmt.Errorf("%s: expected a string or an object, got %s", itemPath, jsonType(item))
+		}
+	}
+	return opts, nil
+}
+
+// parseFieldSort parses a sort option on a single field, returning nil if it
+// cannot be represented by a FieldSortOption.
+func parseFieldSort(m map[string]interface{}, path string) (SortOption, error) {
+	field, body := singleEntry(m)
+	if field == "_script" {
+		return nil, nil
+	}
+
+	opt := FieldSort(field)
+	if order, ok := body.(string); ok {
+		if order != string(OrderAsc) && order != string(OrderDesc) {
+			return nil, nil
+		}
+		return opt.Order(Order(order)), nil
+	}
+
+	p := newParams(body)
+	p.str("order", func(s string) {
+		p.check(s == string(OrderAsc) || s == string(OrderDesc))
+		opt.Order(Order(s))
+	})
+	p.str("mode", func(s string) {
+		switch Mode(s) {
+		case SortModeMin, SortModeMax, SortModeSum, SortModeAvg, SortModeMedian:
+			opt.Mode(Mode(s))
+		default:
+			p.check(false)
+		}
+	})
+	p.str("missing", func(s string) {
+		p.check(s == string(MissingLast) || s == string(MissingFirst))
+		opt.Missing(Missing(s))
+	})
+	p.str("nested_path", func(s string) { opt.NestedPath(s) })
+	if filter, ok := p.get("nested_filter"); ok {
+		q, err := parseQuery(filter, path+"."+field+".nested_filter")
+		if err != nil {
+			return nil, err
+		}
+		// the filter is only rendered along with a nested path
+		p.check(opt.nestedPath != "")
+		opt.NestedFilter(q)
+	}
+	if !p.valid() {
+		return nil, nil
+	}
+	return opt, nil
+}
+
+// parseSource parses the "_source" section of a search request.
+func parseSource(req *SearchRequest, v interface{}, path string) error {
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			req.DisableSource()
+		}
+		return nil
+	case string:
+		req.SourceIncludes(v)
+		return nil
+	case []interface{}:
+		keys, ok := toStrings(v)
+		if !ok {
+			return fmt.Errorf("%s: expected an array of strings", path)
+		}
+		req.SourceIncludes(keys...)
+		return nil
+	}
+
+	p := newParams(v)
+	p.strings("includes", func(keys []string) { req.SourceIncludes(keys...) })
+	p.strings("excludes", func(keys []string) { req.SourceExcludes(keys...) })
+	p.boolean("enabled", func(b bool) {
+		if !b {
+			req.DisableSource()
+		}
+	})
+	if !p.valid() {
+		return fmt.Errorf("%s: unsupported source filter", path)
+	}
+	return nil
+}
+
+// parsePIT parses the "pit" section of a search request.
+func parsePIT(req *SearchRequest, v interface{}, path string) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected an object, got %s", path, jsonType(v))
+	}
+
+	var id string
+	var keepAlive time.Duration
+	for _, key := range sortedKeys(m) {
+		switch key {
+		case "id":
+			if id, ok = m[key].(string); !ok {
+				return fmt.Errorf("%s.id: expected a string, got %s", path, jsonType(m[key]))
+			}
+		case "keep_alive":
+			s, ok := m[key].(string)
+			if !ok {
+				return fmt.Errorf("%s.keep_alive: expected a duration string, got %s", path, jsonType(m[key]))
+			}
+			if keepAlive, ok = parseDuration(s); !ok {
+				req.Custom("pit", v)
+				return nil
+			}
+		default:
+			req.Custom("pit", v)
+			return nil
+		}
+	}
+
+	req.PIT(id, keepAlive)
+	return nil
+}
+
+// parseScriptField parses a script of the "script_fields" section of a
+// search request.
+func parseScriptField(name string, v interface{}, path string) (*ScriptField, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 || m["script"] == nil {
+		return nil, fmt.Errorf("%s: expected an object with a single script", path)
+	}
+
+	script, ok := parseScript(name, m["script"])
+	if !ok {
+		return nil, fmt.Errorf("%s.script: unsupported script", path)
+	}
+	return script, nil
+}
+
+// parseScript parses a script, either in its short form of a source string or
+// as an object. It returns false if the script cannot be represented by a
+// ScriptField.
+func parseScript(name string, v interface{}) (*ScriptField, bool) {
+	script := Script(name)
+	if src, ok := v.(string); ok {
+		return script.Source(src), src != ""
+	}
+
+	p := newParams(v)
+	p.str("source", func(s string) { script.Source(s) })
+	p.object("params", func(m map[string]interface{}) { script.Params(m) })
+	p.str("id", func(s string) { script.ID(s) })
+	p.str("lang", func(s string) { script.Lang(s) })
+	return script, p.valid()
+}
+
+//----------------------------------------------------------------------------//
+
+// parseQuery parses a query from its decoded JSON representation. Queries
+// that cannot be represented by the library's types are returned as custom
+// queries.
+func parseQuery(v interface{}, path string) (Mappable, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a query object, got %s", path, jsonType(v))
+	}
+	if len(m) != 1 {
+		return nil, fmt.Errorf("%s: expected a single query type, got %d", path, len(m))
+	}
+
+	typ, body := singleEntry(m)
+	q, err := parseQueryType(typ, body, path+"."+typ)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return CustomQuery(m), nil
+	}
+	return q, nil
+}
+
+// parseQueryType parses the body of a query of the provided type. It returns
+// nil if the query cannot be represented by the library's types.
+func parseQueryType(typ string, body interface{}, path string) (Mappable, error) {
+	switch typ {
+	case "bool":
+		return parseBoolQuery(body, path)
+	case "boosting":
+		return parseBoostingQuery(body, path)
+	case "constant_score":
+		return parseConstantScoreQuery(body, path)
+	case "dis_max":
+		return parseDisMaxQuery(body, path)
+	case "nested":
+		return parseNestedQuery(body, path)
+	case "match":
+		return parseMatchQuery(TypeMatch, body), nil
+	case "match_bool_prefix":
+		return parseMatchQuery(TypeMatchBoolPrefix, body), nil
+	case "match_phrase":
+		return parseMatchQuery(TypeMatchPhrase, body), nil
+	case "match_phrase_prefix":
+		return parseMatchQuery(TypeMatchPhrasePrefix, body), nil
+	case "multi_match":
+		return parseMultiMatchQuery(body), nil
+	case "match_all":
+		return parseMatchAllQuery(MatchAll(), body), nil
+	case "match_none":
+		return parseMatchAllQuery(MatchNone(), body), nil
+	case "term":
+		return parseTermQuery(body), nil
+	case "terms":
+		return parseTermsQuery(body), nil
+	case "terms_set":
+		return parseTermsSetQuery(body), nil
+	case "exists":
+		return parseExistsQuery(body), nil
+	case "ids":
+		return parseIDsQuery(body), nil
+	case "prefix":
+		return parsePrefixQuery(body), nil
+	case "range":
+		return parseRangeQuery(body), nil
+	case "regexp":
+		return parseRegexpQuery(false, body), nil
+	case "wildcard":
+		return parseRegexpQuery(true, body), nil
+	case "fuzzy":
+		return parseFuzzyQuery(body), nil
+	case "knn":
+		return parseKNNQuery(body), nil
+	}
+	return nil, nil
+}
+
+// parseQueries parses a list of queries, such as a clause of a bool query.
+// A single query is accepted in place of a list.
+func parseQueries(v interface{}, path string) ([]Mappable, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		q, err := parseQuery(v, path)
+		if err != nil {
+			return nil, err
+		}
+		return []Mappable{q}, nil
+	}
+
+	queries := make([]Mappable, len(list))
+	for i, item := range list {
+		q, err := parseQuery(item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	return queries, nil
+}
+
+func parseBoolQuery(body interface{}, path string) (Mappable, error) {
+	q := Bool()
+	p := newParams(body)
+
+	clauses := []struct {
+		key string
+		add func(...Mappable) *BoolQuery
+	}{
+		{"must", q.Must},
+		{"filter", q.Filter},
+		{"must_not", q.MustNot},
+		{"should", q.Should},
+	}
+	for _, clause := range clauses {
+		v, ok := p.get(clause.key)
+		if !ok {
+			continue
+		}
+		queries, err := parseQueries(v, path+"."+clause.key)
+		if err != nil {
+			return nil, err
+		}
+		clause.add(queries...)
+	}
+
+	p.integer("minimum_should_match", math.MaxInt16, func(n uint64) {
+		q.MinimumShouldMatch(int16(n))
+	})
+	p.boost(func(b float32) { q.Boost(b) })
+	p.str("_name", func(s string) { q.Name(s) })
+	return p.result(q), nil
+}
+
+func parseBoostingQuery(body interface{}, path string) (Mappable, error) {
+	q := Boosting()
+	p := newParams(body)
+
+	for _, part := range []struct {
+		key string
+		set func(Mappable) *BoostingQuery
+	}{
+		{"positive", q.Positive},
+		{"negative", q.Negative},
+	} {
+		v, ok := p.get(part.key)
+		if !ok {
+			return nil, nil
+		}
+		sub, err := parseQuery(v, path+"."+part.key)
+		if err != nil {
+			return nil, err
+		}
+		part.set(sub)
+	}
+
+	// the negative boost is always rendered, it must not be defaulted
+	if !p.has("negative_boost") {
+		return nil, nil
+	}
+	p.float("negative_boost", func(f float64) { q.NegativeBoost(float32(f)) })
+	return p.result(q), nil
+}
+
+func parseConstantScoreQuery(body interface{}, path string) (Mappable, error) {
+	p := newParams(body)
+	v, ok := p.get("filter")
+	if !ok {
+		return nil, nil
+	}
+	filter, err := parseQuery(v, path+".filter")
+	if err != nil {
+		return nil, err
+	}
+
+	q := ConstantScore(filter)
+	p.boost(func(b float32) { q.Boost(b) })
+	p.str("_name", func(s string) { q.Name(s) })
+	return p.result(q), nil
+}
+
+func parseDisMaxQuery(body interface{}, path string) (Mappable, error) {
+	p := newParams(body)
+	v, ok := p.get("queries")
+	if !ok {
+		return nil, nil
+	}
+	queries, err := parseQueries(v, path+".queries")
+	if err != nil {
+		return nil, err
+	}
+
+	q := DisMax(queries...)
+	p.float("tie_breaker", func(f float64) { q.TieBreaker(float32(f)) })
+	return p.result(q), nil
+}
+
+func parseNestedQuery(body interface{}, path string) (Mappable, error) {
+	p := newParams(body)
+	v, ok := p.get("query")
+	if !ok || !p.has("path") {
+		return nil, nil
+	}
+	sub, err := parseQuery(v, path+".query")
+	if err != nil {
+		return nil, err
+	}
+
+	q := Nested("", sub)
+	p.str("path", func(s string) { q.path = s })
+	p.str("score_mode", func(s string) { q.ScoreMode(ScoreModeType(s)) })
+	p.object("inner_hits", func(m map[string]interface{}) { q.InnerHits(m) })
+	return p.result(q), nil
+}
+
+func parseMatchQuery(mType matchType, body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	q := newMatch(mType, field)
+	if _, long := v.(map[string]interface{}); !long {
+		return q.Query(v)
+	}
+
+	p := newParams(v)
+	if !p.has("query") {
+		return nil
+	}
+	p.any("query", func(v interface{}) { q.Query(v) })
+	p.str("_name", func(s string) { q.Name(s) })
+	p.str("analyzer", func(s string) { q.Analyzer(s) })
+	p.boolean("auto_generate_synonyms_phrase_query", func(b bool) {
+		q.AutoGenerateSynonymsPhraseQuery(b)
+	})
+	p.str("fuzziness", func(s string) { q.Fuzziness(s) })
+	p.integer("max_expansions", math.MaxUint16, func(n uint64) { q.MaxExpansions(uint16(n)) })
+	p.integer("prefix_length", math.MaxUint16, func(n uint64) { q.PrefixLength(uint16(n)) })
+	p.boolean("fuzzy_transpositions", func(b bool) { q.FuzzyTranspositions(b) })
+	p.str("fuzzy_rewrite", func(s string) { q.FuzzyRewrite(s) })
+	p.boolean("lenient", func(b bool) { q.Lenient(b) })
+	p.str("operator", func(s string) {
+		op, ok := parseEnum[MatchOperator](s)
+		p.check(ok)
+		q.Operator(op)
+	})
+	p.str("minimum_should_match", func(s string) { q.MinimumShouldMatch(s) })
+	p.str("zero_terms_query", func(s string) {
+		zt, ok := parseEnum[ZeroTerms](s)
+		p.check(ok)
+		q.ZeroTermsQuery(zt)
+	})
+	p.integer("slop", math.MaxUint16, func(n uint64) { q.Slop(uint16(n)) })
+	return p.result(q)
+}
+
+func parseMultiMatchQuery(body interface{}) Mappable {
+	q := MultiMatch()
+	p := newParams(body)
+	if !p.has("query") || !p.has("fields") {
+		return nil
+	}
+
+	p.any("query", func(v interface{}) { q.Query(v) })
+	p.strings("fields", func(fields []string) {
+		// an empty list of fields is rendered as null by the builder
+		p.check(len(fields) > 0)
+		q.Fields(fields...)
+	})
+	p.str("type", func(s string) {
+		t, ok := parseEnum[MultiMatchType](s)
+		p.check(ok)
+		q.Type(t)
+	})
+	p.float("tie_breaker", func(f float64) { q.TieBreaker(float32(f)) })
+	p.boost(func(b float32) { q.Boost(b) })
+	p.str("_name", func(s string) { q.Name(s) })
+	p.str("analyzer", func(s string) { q.Analyzer(s) })
+	p.boolean("auto_generate_synonyms_phrase_query", func(b bool) {
+		q.AutoGenerateSynonymsPhraseQuery(b)
+	})
+	p.str("fuzziness", func(s string) { q.Fuzziness(s) })
+	p.integer("max_expansions", math.MaxUint16, func(n uint64) { q.MaxExpansions(uint16(n)) })
+	p.integer("prefix_length", math.MaxUint16, func(n uint64) { q.PrefixLength(uint16(n)) })
+	p.boolean("fuzzy_transpositions", func(b bool) { q.FuzzyTranspositions(b) })
+	p.str("fuzzy_rewrite", func(s string) { q.FuzzyRewrite(s) })
+	p.boolean("lenient", func(b bool) { q.Lenient(b) })
+	p.str("operator", func(s string) {
+		op, ok := parseEnum[MatchOperator](s)
+		p.check(ok)
+		q.Operator(op)
+	})
+	p.str("minimum_should_match", func(s string) { q.MinimumShouldMatch(s) })
+	p.str("zero_terms_query", func(s string) {
+		zt, ok := parseEnum[ZeroTerms](s)
+		p.check(ok)
+		q.ZeroTermsQuery(zt)
+	})
+	p.integer("slop", math.MaxUint16, func(n uint64) { q.Slop(uint16(n)) })
+	return p.result(q)
+}
+
+func parseMatchAllQuery(q *MatchAllQuery, body interface{}) Mappable {
+	p := newParams(body)
+	if q.all {
+		p.boost(func(b float32) { q.Boost(b) })
+	}
+	return p.result(q)
+}
+
+func parseTermQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	q := Term(field, v)
+	if _, long := v.(map[string]interface{}); !long {
+		return q
+	}
+
+	p := newParams(v)
+	if !p.has("value") {
+		return nil
+	}
+	p.any("value", func(v interface{}) { q.Value(v) })
+	p.boost(func(b float32) { q.Boost(b) })
+	p.boolean("case_insensitive", func(b bool) { q.CaseInsensitive(b) })
+	p.str("_name", func(s string) { q.Name(s) })
+	return p.result(q)
+}
+
+func parseTermsQuery(body interface{}) Mappable {
+	m, ok := body.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var q *TermsQuery
+	for key, v := range m {
+		if key == "boost" {
+			continue
+		}
+		values, ok := v.([]interface{})
+		if !ok || q != nil {
+			// terms lookups and multiple fields are not supported
+			return nil
+		}
+		q = Terms(key, values...)
+	}
+	if q == nil {
+		return nil
+	}
+
+	if v, ok := m["boost"]; ok {
+		// only positive boosts are rendered
+		b, ok := toFloat(v)
+		if !ok || b <= 0 {
+			return nil
+		}
+		q.Boost(float32(b))
+	}
+	return q
+}
+
+func parseTermsSetQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	q := TermsSet(field)
+	p := newParams(v)
+	if !p.has("terms") {
+		return nil
+	}
+	p.strings("terms", func(terms []string) { q.Terms(terms...) })
+	p.str("minimum_should_match_field", func(s string) { q.MinimumShouldMatchField(s) })
+	p.str("minimum_should_match_script", func(s string) { q.MinimumShouldMatchScript(s) })
+	return p.result(q)
+}
+
+func parseExistsQuery(body interface{}) Mappable {
+	q := Exists("")
+	p := newParams(body)
+	if !p.has("field") {
+		return nil
+	}
+	p.str("field", func(s string) { q.Field = s })
+	return p.result(q)
+}
+
+func parseIDsQuery(body interface{}) Mappable {
+	q := IDs()
+	p := newParams(body)
+	if !p.has("values") {
+		return nil
+	}
+	p.strings("values", func(values []string) { q.IDs.Values = values })
+	return p.result(q)
+}
+
+func parsePrefixQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	if value, ok := v.(string); ok {
+		return Prefix(field, value)
+	}
+
+	q := Prefix(field, "")
+	p := newParams(v)
+	if !p.has("value") {
+		return nil
+	}
+	p.str("value", func(s string) { q.params.Value = s })
+	p.str("rewrite", func(s string) { q.Rewrite(s) })
+	return p.result(q)
+}
+
+func parseRangeQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	q := Range(field)
+	p := newParams(v)
+	p.any("gt", func(v interface{}) { q.Gt(v) })
+	p.any("gte", func(v interface{}) { q.Gte(v) })
+	p.any("lt", func(v interface{}) { q.Lt(v) })
+	p.any("lte", func(v interface{}) { q.Lte(v) })
+	p.str("format", func(s string) { q.Format(s) })
+	p.str("relation", func(s string) {
+		rel, ok := parseEnum[RangeRelation](s)
+		p.check(ok)
+		q.Relation(rel)
+	})
+	p.str("time_zone", func(s string) { q.TimeZone(s) })
+	p.boost(func(b float32) { q.Boost(b) })
+	return p.result(q)
+}
+
+func parseRegexpQuery(wildcard bool, body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	newQuery := Regexp
+	if wildcard {
+		newQuery = Wildcard
+	}
+	if value, ok := v.(string); ok {
+		return newQuery(field, value)
+	}
+
+	q := newQuery(field, "")
+	p := newParams(v)
+	if !p.has("value") {
+		return nil
+	}
+	p.str("value", func(s string) { q.Value(s) })
+	if !wildcard {
+		p.str("flags", func(s string) { q.Flags(s) })
+		p.integer("max_determinized_states", math.MaxUint16, func(n uint64) {
+			q.MaxDeterminizedStates(uint16(n))
+		})
+	}
+	p.str("rewrite", func(s string) { q.Rewrite(s) })
+	return p.result(q)
+}
+
+func parseFuzzyQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	if value, ok := v.(string); ok {
+		return Fuzzy(field, value)
+	}
+
+	q := Fuzzy(field, "")
+	p := newParams(v)
+	if !p.has("value") {
+		return nil
+	}
+	p.str("value", func(s string) { q.Value(s) })
+	p.str("fuzziness", func(s string) { q.Fuzziness(s) })
+	p.integer("max_expansions", math.MaxUint16, func(n uint64) { q.MaxExpansions(uint16(n)) })
+	p.integer("prefix_length", math.MaxUint16, func(n uint64) { q.PrefixLength(uint16(n)) })
+	p.boolean("transpositions", func(b bool) { q.Transpositions(b) })
+	p.str("rewrite", func(s string) { q.Rewrite(s) })
+	return p.result(q)
+}
+
+func parseKNNQuery(body interface{}) Mappable {
+	field, v, ok := fieldQuery(body)
+	if !ok {
+		return nil
+	}
+
+	q := KNN(field, nil)
+	p := newParams(v)
+	if !p.has("vector") {
+		return nil
+	}
+	p.any("vector", func(v interface{}) {
+		list, ok := v.([]interface{})
+		p.check(ok)
+		vector, ok := toFloats(list)
+		p.check(ok)
+		q.vector = vector
+	})
+	p.integer("k", math.MaxInt32, func(n uint64) { q.K(int(n)) })
+	p.float("max_distance", func(f float64) { q.MaxDistance(f) })
+	p.float("min_score", func(f float64) { q.MinScore(f) })
+	p.object("filter", func(m map[string]interface{}) { q.Filter(m) })
+	p.object("method_parameters", func(m map[string]interface{}) { q.MethodParameters(m) })
+	p.any("rescore", func(v interface{}) { q.Rescore(v) })
+	p.boolean("expand_nested_docs", func(b bool) { q.ExpandNestedDocs(b) })
+	return p.result(q)
+}
+
+// fieldQuery returns the field and parameters of a field-level query, whose
+// body must hold a single field.
+func fieldQuery(body interface{}) (string, interface{}, bool) {
+	m, ok := body.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+	field, v := singleEntry(m)
+	return field, v, true
+}
+
+//----------------------------------------------------------------------------//
+
+// parseAggs parses an object of named aggregations. Aggregations are returned
+// sorted by name.
+func parseAggs(v interface{}, path string) ([]Aggregation, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an object of aggregations, got %s", path, jsonType(v))
+	}
+
+	aggs := make([]Aggregation, 0, len(m))
+	for _, name := range sortedKeys(m) {
+		agg, err := parseAgg(name, m[name], path+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		aggs = append(aggs, agg)
+	}
+	return aggs, nil
+}
+
+// parseAgg parses a single aggregation. Aggregations that cannot be
+// represented by the library's types are returned as custom aggregations.
+func parseAgg(name string, v interface{}, path string) (Aggregation, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an aggregation object, got %s", path, jsonType(v))
+	}
+
+	var types []string
+	var subAggs []Aggregation
+	for _, key := range sortedKeys(m) {
+		if key != "aggs" && key != "aggregations" {
+			types = append(types, key)
+			continue
+		}
+		aggs, err := parseAggs(m[key], path+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		subAggs = append(subAggs, aggs...)
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("%s: missing aggregation type", path)
+	}
+
+	// additional keys, such as "meta", are not supported by the library
+	var agg Aggregation
+	if len(types) == 1 {
+		var err error
+		agg, err = parseAggType(name, types[0], m[types[0]], subAggs, path+"."+types[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if agg == nil {
+		return CustomAgg(name, m), nil
+	}
+	return agg, nil
+}
+
+// parseAggType parses the body of an aggregation of the provided type. It
+// returns nil if the aggregation cannot be represented by the library's types.
+func parseAggType(
+	name, typ string,
+	body interface{},
+	subAggs []Aggregation,
+	path string,
+) (Aggregation, error) {
+	switch typ {
+	case "terms":
+		return parseTermsAgg(name, body, subAggs), nil
+	case "date_histogram":
+		return parseDateHistogramAgg(name, body, subAggs), nil
+	case "histogram":
+		return parseHistogramAgg(name, body, subAggs), nil
+	case "filter":
+		filter, err := parseQuery(body, path)
+		if err != nil {
+			return nil, err
+		}
+		return FilterAgg(name, filter).Aggs(subAggs...), nil
+	case "nested":
+		agg := NestedAgg(name, "")
+		p := newParams(body)
+		if !p.has("path") {
+			return nil, nil
+		}
+		p.str("path", func(s string) { agg.Path(s) })
+		return aggResult(p, agg.Aggs(subAggs...)), nil
+	}
+
+	// metric aggregations do not support sub-aggregations
+	if len(subAggs) > 0 {
+		return nil, nil
+	}
+	switch typ {
+	case "avg":
+		agg := Avg(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, true), agg), nil
+	case "max":
+		agg := Max(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, true), agg), nil
+	case "min":
+		agg := Min(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, true), agg), nil
+	case "sum":
+		agg := Sum(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, true), agg), nil
+	case "stats":
+		agg := Stats(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, true), agg), nil
+	case "value_count":
+		agg := ValueCount(name, "")
+		return aggResult(parseBaseAgg(agg.BaseAgg, body, false), agg), nil
+	case "cardinality":
+		agg := Cardinality(name, "")
+		p := parseBaseAgg(agg.BaseAgg, body, true)
+		p.integer("precision_threshold", math.MaxUint16, func(n uint64) {
+			agg.PrecisionThreshold(uint16(n))
+		})
+		return aggResult(p, agg), nil
+	}
+	return nil, nil
+}
+
+// parseBaseAgg parses the parameters common to most metric aggregations.
+func parseBaseAgg(agg *BaseAgg, body interface{}, missing bool) *params {
+	p := newParams(body)
+	p.check(p.has("field"))
+	p.str("field", func(s string) { agg.Field = s })
+	if missing {
+		p.any("missing", func(v interface{}) { agg.Miss = v })
+	}
+	return p
+}
+
+// aggResult returns agg if all parameters have been parsed, and nil otherwise.
+func aggResult(p *params, agg Aggregation) Aggregation {
+	if !p.valid() {
+		return nil
+	}
+	return agg
+}
+
+func parseTermsAgg(name string, body interface{}, subAggs []Aggregation) Aggregation {
+	agg := TermsAgg(name, "")
+	p := newParams(body)
+	p.check(p.has("field"))
+	p.str("field", func(s string) { agg.field = s })
+	p.integer("size", math.MaxUint64, func(n uint64) { agg.Size(n) })
+	p.float("shard_size", func(f float64) { agg.ShardSize(f) })
+	p.boolean("show_term_doc_count_error", func(b bool) { agg.ShowTermDocCountError(b) })
+	p.order(func(order map[string]string) { agg.Order(order) })
+	if v, ok := p.get("include"); ok {
+		switch v := v.(type) {
+		case string:
+			agg.Include(v)
+		case []interface{}:
+			include, ok := toStrings(v)
+			p.check(ok && len(include) > 0)
+			agg.Include(include...)
+		default:
+			// regular expressions with flags and partitions are not supported
+			p.check(false)
+		}
+	}
+	if len(subAggs) > 0 {
+		agg.Aggs(subAggs...)
+	}
+	return aggResult(p, agg)
+}
+
+func parseDateHistogramAgg(name string, body interface{}, subAggs []Aggregation) Aggregation {
+	agg := DateHistogramAgg(name, "")
+	p := newParams(body)
+	p.check(p.has("field"))
+	p.str("field", func(s string) { agg.field = s })
+	p.str("calendar_interval", func(s string) { agg.CalendarInterval(s) })
+	p.str("fixed_interval", func(s string) { agg.FixedInterval(s) })
+	p.str("time_zone", func(s string) { agg.TimeZone(s) })
+	p.str("offset", func(s string) { agg.Offset(s) })
+	p.str("format", func(s string) { agg.Format(s) })
+	p.integer("min_doc_count", math.MaxUint64, func(n uint64) { agg.MinDocCount(n) })
+	p.bounds("extended_bounds", func(min, max interface{}) { agg.ExtendedBounds(min, max) })
+	p.bounds("hard_bounds", func(min, max interface{}) { agg.HardBounds(min, max) })
+	p.boolean("keyed", func(b bool) { agg.Keyed(b) })
+	p.any("missing", func(v interface{}) { agg.Missing(v) })
+	p.order(func(order map[string]string) { agg.Order(order) })
+	if len(subAggs) > 0 {
+		agg.Aggs(subAggs...)
+	}
+	return aggResult(p, agg)
+}
+
+func parseHistogramAgg(name string, body interface{}, subAggs []Aggregation) Aggregation {
+	agg := HistogramAgg(name, "", 0)
+	p := newParams(body)
+	p.check(p.has("field") && p.has("interval"))
+	p.str("field", func(s string) { agg.field = s })
+	p.float("interval", func(f float64) { agg.Interval(f) })
+	p.float("offset", func(f float64) { agg.Offset(f) })
+	p.integer("min_doc_count", math.MaxUint64, func(n uint64) { agg.MinDocCount(n) })
+	for _, key := range []string{"extended_bounds", "hard_bounds"} {
+		p.bounds(key, func(min, max interface{}) {
+			lo, okMin := toFloat(min)
+			hi, okMax := toFloat(max)
+			p.check(okMin && okMax)
+			if key == "extended_bounds" {
+				agg.ExtendedBounds(lo, hi)
+			} else {
+				agg.HardBounds(lo, hi)
+			}
+		})
+	}
+	p.boolean("keyed", func(b bool) { agg.Keyed(b) })
+	p.any("missing", func(v interface{}) { agg.Missing(v) })
+	p.order(func(order map[string]string) { agg.Order(order) })
+	if len(subAggs) > 0 {
+		agg.Aggs(subAggs...)
+	}
+	return aggResult(p, agg)
+}
+
+//----------------------------------------------------------------------------//
+
+// params reads the parameters of a decoded JSON object into a builder. Every
+// parameter read is marked as used; the object is only valid if it is an
+// object, all its parameters have been used, and all had a value the builder
+// can represent. Setters are only called for parameters present in the object.
+type params struct {
+	m    map[string]interface{}
+	used map[string]bool
+	ok   bool
+}
+
+func newParams(v interface{}) *params {
+	m, ok := v.(map[string]interface{})
+	return &params{
+		m:    m,
+		used: make(map[string]bool, len(m)),
+		ok:   ok,
+	}
+}
+
+// has returns whether the object holds the provided parameter, without
+// marking it as used.
+func (p *params) has(key string) bool {
+	_, ok := p.m[key]
+	return ok
+}
+
+// get returns the value of the provided parameter and marks it as used.
+func (p *params) get(key string) (interface{}, bool) {
+	v, ok := p.m[key]
+	if ok {
+		p.used[key] = true
+	}
+	return v, ok
+}
+
+// check marks the object as invalid if ok is false.
+func (p *params) check(ok bool) {
+	p.ok = p.ok && ok
+}
+
+// valid returns whether the whole object has been read successfully.
+func (p *params) valid() bool {
+	return p.ok && len(p.used) == len(p.m)
+}
+
+// result returns q if the object is valid, and nil otherwise.
+func (p *params) result(q Mappable) Mappable {
+	if !p.valid() {
+		return nil
+	}
+	return q
+}
+
+func (p *params) any(key string, set func(interface{})) {
+	if v, ok := p.get(key); ok {
+		set(v)
+	}
+}
+
+// str reads a string parameter. Empty strings are omitted by the builders,
+// and thus not supported.
+func (p *params) str(key string, set func(string)) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	s, ok := v.(string)
+	p.check(ok && s != "")
+	if ok {
+		set(s)
+	}
+}
+
+func (p *params) boolean(key string, set func(bool)) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	b, ok := v.(bool)
+	p.check(ok)
+	set(b)
+}
+
+func (p *params) float(key string, set func(float64)) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	f, ok := toFloat(v)
+	p.check(ok)
+	set(f)
+}
+
+// integer reads a non-negative integer parameter no greater than max.
+func (p *params) integer(key string, max uint64, set func(uint64)) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	n, ok := toUint(v, max)
+	p.check(ok)
+	set(n)
+}
+
+// boost reads the "boost" parameter. A boost of zero is omitted by the
+// builders, and thus not supported.
+func (p *params) boost(set func(float32)) {
+	p.float("boost", func(f float64) {
+		p.check(f != 0)
+		set(float32(f))
+	})
+}
+
+func (p *params) strings(key string, set func([]string)) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	list, ok := v.([]interface{})
+	p.check(ok)
+	strs, ok := toStrings(list)
+	p.check(ok)
+	set(strs)
+}
+
+func (p *params) object(key string, set func(map[string]interface{})) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	m, ok := v.(map[string]interface{})
+	p.check(ok)
+	set(m)
+}
+
+// order reads the "order" parameter of bucket aggregations, supported as a
+// single object of string values.
+func (p *params) order(set func(map[string]string)) {
+	p.object("order", func(m map[string]interface{}) {
+		order := make(map[string]string, len(m))
+		for key, v := range m {
+			s, ok := v.(string)
+			p.check(ok)
+			order[key] = s
+		}
+		set(order)
+	})
+}
+
+// bounds reads a bounds parameter of histogram aggregations, which must hold
+// both a "min" and a "max" value.
+func (p *params) bounds(key string, set func(min, max interface{})) {
+	p.object(key, func(m map[string]interface{}) {
+		min, okMin := m["min"]
+		max, okMax := m["max"]
+		p.check(okMin && okMax && len(m) == 2)
+		set(min, max)
+	})
+}
+
+//----------------------------------------------------------------------------//
+
+// decodeJSON decodes a JSON value, with integer numbers decoded as int64 and
+// other numbers as float64.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed decoding JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("failed decoding JSON: unexpected data after top-level value")
+	}
+
+	return normalizeNumbers(v), nil
+}
+
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case map[string]interface{}:
+		for key, e := range v {
+			v[key] = normalizeNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	}
+	return v
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toUint(v interface{}, max uint64) (uint64, bool) {
+	n, ok := v.(int64)
+	if !ok || n < 0 || uint64(n) > max {
+		return 0, false
+	}
+	return uint64(n), true
+}
+
+func toStrings(list []interface{}) ([]string, bool) {
+	strs := make([]string, len(list))
+	for i, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		strs[i] = s
+	}
+	return strs, true
+}
+
+func toFloats(list []interface{}) ([]float64, bool) {
+	floats := make([]float64, len(list))
+	for i, v := range list {
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, false
+		}
+		floats[i] = f
+	}
+	return floats, true
+}
+
+// parseDuration parses a duration in the format known to OpenSearch, such as
+// "30s", "1m" or "1d". It returns false if s is not a valid duration.
+func parseDuration(s string) (time.Duration, bool) {
+	// days, micros and nanos are not known to time.ParseDuration
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.ParseInt(n, 10, 64)
+		if err != nil || days < 0 || days > math.MaxInt64/int64(24*time.Hour) {
+			return 0, false
+		}
+		return time.Duration(days) * 24 * time.Hour, true
+	}
+	if n, ok := strings.CutSuffix(s, "micros"); ok {
+		s = n + "us"
+	} else if n, ok := strings.CutSuffix(s, "nanos"); ok {
+		s = n + "ns"
+	}
+	dur, err := time.ParseDuration(s)
+	return dur, err == nil
+}
+
+// parseEnum returns the value of an enumeration type whose string
+// representation matches s, ignoring case.
+func parseEnum[E interface {
+	~uint8
+	String() string
+}](s string) (E, bool) {
+	for i := 0; i <= math.MaxUint8; i++ {
+		if e := E(i); e.String() != "" && strings.EqualFold(e.String(), s) {
+			return e, true
+		}
+	}
+	return 0, false
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func singleEntry(m map[string]interface{}) (string, interface{}) {
+	for key, v := range m {
+		return key, v
+	}
+	return "", nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,387 @@
+package osquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		expType Mappable
+		exp     string
+	}{
+		{
+			"term in short form",
+			`{"term": {"tag": "go"}}`,
+			&TermQuery{},
+			`{"term": {"tag": {"value": "go"}}}`,
+		},
+		{
+			"term in long form",
+			`{"term": {"views": {"value": 10, "boost": 1.5, "case_insensitive": true, "_name": "views"}}}`,
+			&TermQuery{},
+			`{"term": {"views": {"value": 10, "boost": 1.5, "case_insensitive": true, "_name": "views"}}}`,
+		},
+		{
+			"term with a zero boost",
+			`{"term": {"tag": {"value": "go", "boost": 0}}}`,
+			&CustomQueryMap{},
+			`{"term": {"tag": {"value": "go", "boost": 0}}}`,
+		},
+		{
+			"terms with boost",
+			`{"terms": {"tag": ["go", "rust"], "boost": 2}}`,
+			&TermsQuery{},
+			`{"terms": {"tag": ["go", "rust"], "boost": 2}}`,
+		},
+		{
+			"terms lookup",
+			`{"terms": {"tag": {"index": "tags", "id": "1", "path": "tags"}}}`,
+			&CustomQueryMap{},
+			`{"terms": {"tag": {"index": "tags", "id": "1", "path": "tags"}}}`,
+		},
+		{
+			"match in short form",
+			`{"match": {"title": "go and stuff"}}`,
+			&MatchQuery{},
+			`{"match": {"title": {"query": "go and stuff"}}}`,
+		},
+		{
+			"match_phrase in long form",
+			`{"match_phrase": {"title": {"query": "go and stuff", "slop": 2, "operator": "and", "zero_terms_query": "all"}}}`,
+			&MatchQuery{},
+			`{"match_phrase": {"title": {"query": "go and stuff", "slop": 2, "operator": "AND", "zero_terms_query": "all"}}}`,
+		},
+		{
+			"match with unsupported parameter",
+			`{"match": {"title": {"query": "go", "boost": 2}}}`,
+			&CustomQueryMap{},
+			`{"match": {"title": {"query": "go", "boost": 2}}}`,
+		},
+		{
+			"multi_match",
+			`{"multi_match": {"query": "go", "fields": ["title", "body^2"], "type": "best_fields", "tie_breaker": 0.3}}`,
+			&MultiMatchQuery{},
+			`{"multi_match": {"query": "go", "fields": ["title", "body^2"], "tie_breaker": 0.3}}`,
+		},
+		{
+			"range",
+			`{"range": {"@timestamp": {"gte": "now-1d/d", "lt": 0, "relation": "within", "time_zone": "+01:00"}}}`,
+			&RangeQuery{},
+			`{"range": {"@timestamp": {"gte": "now-1d/d", "lt": 0, "relation": "WITHIN", "time_zone": "+01:00"}}}`,
+		},
+		{
+			"exists",
+			`{"exists": {"field": "title"}}`,
+			&ExistsQuery{},
+			`{"exists": {"field": "title"}}`,
+		},
+		{
+			"ids",
+			`{"ids": {"values": ["1", "2"]}}`,
+			&IDsQuery{},
+			`{"ids": {"values": ["1", "2"]}}`,
+		},
+		{
+			"prefix in short form",
+			`{"prefix": {"title": "go"}}`,
+			&PrefixQuery{},
+			`{"prefix": {"title": {"value": "go"}}}`,
+		},
+		{
+			"regexp",
+			`{"regexp": {"title": {"value": "go.*", "flags": "ALL", "max_determinized_states": 10000}}}`,
+			&RegexpQuery{},
+			`{"regexp": {"title": {"value": "go.*", "flags": "ALL", "max_determinized_states": 10000}}}`,
+		},
+		{
+			"wildcard with flags",
+			`{"wildcard": {"title": {"value": "go*", "flags": "ALL"}}}`,
+			&CustomQueryMap{},
+			`{"wildcard": {"title": {"value": "go*", "flags": "ALL"}}}`,
+		},
+		{
+			"fuzzy",
+			`{"fuzzy": {"title": {"value": "og", "fuzziness": "AUTO", "transpositions": false}}}`,
+			&FuzzyQuery{},
+			`{"fuzzy": {"title": {"value": "og", "fuzziness": "AUTO", "transpositions": false}}}`,
+		},
+		{
+			"knn",
+			`{"knn": {"embedding": {"vector": [0.5, -1], "k": 10, "method_parameters": {"ef_search": 100}}}}`,
+			&KNNQuery{},
+			`{"knn": {"embedding": {"vector": [0.5, -1], "k": 10, "method_parameters": {"ef_search": 100}}}}`,
+		},
+		{
+			"knn with invalid vector",
+			`{"knn": {"embedding": {"vector": ["a"], "k": 10}}}`,
+			&CustomQueryMap{},
+			`{"knn": {"embedding": {"vector": ["a"], "k": 10}}}`,
+		},
+		{
+			"match_all",
+			`{"match_all": {"boost": 1.2}}`,
+			&MatchAllQuery{},
+			`{"match_all": {"boost": 1.2}}`,
+		},
+		{
+			"bool with mixed clauses",
+			`{"bool": {
+				"must": [{"match": {"title": "go"}}, {"geo_distance": {"distance": "10km", "location": [1, 2]}}],
+				"filter": {"term": {"tag": "tech"}},
+				"must_not": [],
+				"minimum_should_match": 1,
+				"_name": "posts"
+			}}`,
+			&BoolQuery{},
+			`{"bool": {
+				"must": [{"match": {"title": {"query": "go"}}}, {"geo_distance": {"distance": "10km", "location": [1, 2]}}],
+				"filter": [{"term": {"tag": {"value": "tech"}}}],
+				"minimum_should_match": 1,
+				"_name": "posts"
+			}}`,
+		},
+		{
+			"bool with percentage minimum_should_match",
+			`{"bool": {"should": [{"term": {"tag": "go"}}], "minimum_should_match": "50%"}}`,
+			&CustomQueryMap{},
+			`{"bool": {"should": [{"term": {"tag": "go"}}], "minimum_should_match": "50%"}}`,
+		},
+		{
+			"nested",
+			`{"nested": {"path": "comments", "query": {"term": {"comments.author": "bob"}}, "score_mode": "max"}}`,
+			&NestedQuery{},
+			`{"nested": {"path": "comments", "query": {"term": {"comments.author": {"value": "bob"}}}, "score_mode": "max"}}`,
+		},
+		{
+			"constant_score",
+			`{"constant_score": {"filter": {"exists": {"field": "title"}}, "boost": 3}}`,
+			&ConstantScoreQuery{},
+			`{"constant_score": {"filter": {"exists": {"field": "title"}}, "boost": 3}}`,
+		},
+		{
+			"boosting",
+			`{"boosting": {"positive": {"match_all": {}}, "negative": {"term": {"tag": "old"}}, "negative_boost": 0.5}}`,
+			&BoostingQuery{},
+			`{"boosting": {"positive": {"match_all": {}}, "negative": {"term": {"tag": {"value": "old"}}}, "negative_boost": 0.5}}`,
+		},
+		{
+			"dis_max",
+			`{"dis_max": {"queries": [{"match": {"title": "go"}}, {"match": {"body": "go"}}], "tie_breaker": 0.7}}`,
+			&DisMaxQuery{},
+			`{"dis_max": {"queries": [{"match": {"title": {"query": "go"}}}, {"match": {"body": {"query": "go"}}}], "tie_breaker": 0.7}}`,
+		},
+		{
+			"unknown query type",
+			`{"geo_bounding_box": {"location": {"top_left": [0, 1], "bottom_right": [1, 0]}}}`,
+			&CustomQueryMap{},
+			`{"geo_bounding_box": {"location": {"top_left": [0, 1], "bottom_right": [1, 0]}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery([]byte(test.input))
+			assert.Nil(t, err)
+			assert.Equal(t, typeName(test.expType), typeName(q))
+			assertJSON(t, test.exp, q.Map())
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			"invalid JSON",
+			`{"term": `,
+			"failed decoding JSON: unexpected EOF",
+		},
+		{
+			"trailing data",
+			`{"term": {"tag": "go"}} {}`,
+			"failed decoding JSON: unexpected data after top-level value",
+		},
+		{
+			"not an object",
+			`["term"]`,
+			"query: expected a query object, got array",
+		},
+		{
+			"multiple query types",
+			`{"term": {"tag": "go"}, "match": {"title": "go"}}`,
+			"query: expected a single query type, got 2",
+		},
+		{
+			"invalid sub-query",
+			`{"bool": {"filter": [{"term": {"tag": "go"}}, "tech"]}}`,
+			"query.bool.filter[1]: expected a query object, got string",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseQuery([]byte(test.input))
+			assert.NotNil(t, err)
+			assert.Equal(t, test.exp, err.Error())
+		})
+	}
+}
+
+func TestParseSearchRequest(t *testing.T) {
+	req, err := ParseSearchRequest([]byte(`{
+		"query": {"bool": {"must": {"match": {"title": "go"}}}},
+		"post_filter": {"term": {"tag": "tech"}},
+		"aggs": {
+			"tags": {
+				"terms": {"field": "tag", "size": 5, "order": {"_count": "desc"}},
+				"aggregations": {
+					"avg_views": {"avg": {"field": "views", "missing": 0}},
+					"top": {"top_hits": {"size": 1}}
+				}
+			},
+			"per_day": {"date_histogram": {"field": "@timestamp", "calendar_interval": "day", "min_doc_count": 0}},
+			"with_meta": {"max": {"field": "views"}, "meta": {"color": "blue"}}
+		},
+		"sort": [{"@timestamp": "desc"}, "_score", {"title": {"order": "asc", "unmapped_type": "keyword"}}],
+		"size": 20,
+		"from": 40,
+		"timeout": "10s",
+		"_source": ["title", "tag"],
+		"search_after": [1700000000000, "abc"]
+	}`))
+	assert.Nil(t, err)
+
+	// the parsed request can be modified with the builder API
+	req.query.(*BoolQuery).Filter(Range("views").Gte(10))
+
+	assertJSON(t, `{
+		"query": {"bool": {
+			"must": [{"match": {"title": {"query": "go"}}}],
+			"filter": [{"range": {"views": {"gte": 10}}}]
+		}},
+		"post_filter": {"term": {"tag": {"value": "tech"}}},
+		"aggs": {
+			"tags": {
+				"terms": {"field": "tag", "size": 5, "order": {"_count": "desc"}},
+				"aggs": {
+					"avg_views": {"avg": {"field": "views", "missing": 0}},
+					"top": {"top_hits": {"size": 1}}
+				}
+			},
+			"per_day": {"date_histogram": {"field": "@timestamp", "calendar_interval": "day", "min_doc_count": 0}},
+			"with_meta": {"max": {"field": "views"}, "meta": {"color": "blue"}}
+		},
+		"sort": [{"@timestamp": {"order": "desc"}}, {"_score": {}}, {"title": {"order": "asc", "unmapped_type": "keyword"}}],
+		"size": 20,
+		"from": 40,
+		"timeout": "10s",
+		"_source": {"includes": ["title", "tag"]},
+		"search_after": [1700000000000, "abc"]
+	}`, req.Map())
+
+	assert.Equal(t, 3, len(req.aggs))
+	tags, ok := req.aggs[1].(*TermsAggregation)
+	assert.True(t, ok)
+	assert.Equal(t, "tags", tags.Name())
+	assert.Equal(t, typeName(&AvgAgg{}), typeName(tags.aggs[0]))
+	assert.Equal(t, typeName(&CustomAggMap{}), typeName(tags.aggs[1]))
+	assert.Equal(t, typeName(&CustomAggMap{}), typeName(req.aggs[2]))
+}
+
+func TestParseSearchRequestCustomFields(t *testing.T) {
+	req, err := ParseSearchRequest([]byte(`{
+		"query": {"match_all": {}},
+		"track_total_hits": true,
+		"collapse": {"field": "user"},
+		"timeout": "1500ms"
+	}`))
+	assert.Nil(t, err)
+
+	exp := `{
+		"query": {"match_all": {}},
+		"track_total_hits": true,
+		"collapse": {"field": "user"},
+		"timeout": "1500ms"
+	}`
+	assertJSON(t, exp, req.Map())
+
+	data, err := req.MarshalJSON()
+	assert.Nil(t, err)
+	assertJSON(t, exp, mustDecodeObject(t, data))
+
+	// durations are parsed in OpenSearch time units, and kept as is if they
+	// cannot be parsed
+	req, err = ParseSearchRequest([]byte(`{"timeout": "1d", "pit": {"id": "abc", "keep_alive": "2d"}}`))
+	assert.Nil(t, err)
+	assertJSON(t, `{"timeout": "86400s", "pit": {"id": "abc", "keep_alive": "172800s"}}`, req.Map())
+
+	req, err = ParseSearchRequest([]byte(`{"timeout": "1w", "pit": {"id": "abc", "keep_alive": "1y"}}`))
+	assert.Nil(t, err)
+	assertJSON(t, `{"timeout": "1w", "pit": {"id": "abc", "keep_alive": "1y"}}`, req.Map())
+
+	// fields set by the builder take precedence
+	req.Size(5).Custom("size", 10)
+	assert.Equal(t, uint64(5), req.Map()["size"])
+}
+
+func mustDecodeObject(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("invalid JSON: %s", err)
+	}
+	return m
+}
+
+func TestParseSearchRequestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			"negative size",
+			`{"size": -1}`,
+			"size: expected a non-negative integer, got number",
+		},
+		{
+			"invalid sub-aggregation",
+			`{"aggs": {"tags": {"terms": {"field": "tag"}, "aggs": {"avg_views": {}}}}}`,
+			"aggs.tags.aggs.avg_views: missing aggregation type",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSearchRequest([]byte(test.input))
+			assert.NotNil(t, err)
+			assert.Equal(t, test.exp, err.Error())
+		})
+	}
+}
+
+func assertJSON(t *testing.T, exp string, got map[string]interface{}) {
+	t.Helper()
+
+	var expMap map[string]interface{}
+	if err := json.Unmarshal([]byte(exp), &expMap); err != nil {
+		t.Fatalf("invalid expected JSON: %s", err)
+	}
+	expJSON, gotJSON, ok := sameJSON(expMap, got)
+	if !ok {
+		t.Errorf("expected %s, got %s", expJSON, gotJSON)
+	}
+}
+
+func typeName(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
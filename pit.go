@@ -0,0 +1,300 @@
+package osquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// PointInTime represents a point in time (PIT), a consistent view of one or
+// more indices that searches can be run against, as described in
+// https://opensearch.org/docs/latest/search-plugins/searching-data/point-in-time/
+type PointInTime struct {
+	// ID is the identifier of the PIT. OpenSearch may return an updated
+	// identifier with every search, which should be used for the next one.
+	ID string
+
+	// KeepAlive is how long the PIT is kept open after each search.
+	KeepAlive time.Duration
+}
+
+// CreatePIT creates a new point in time over the provided indices, kept alive
+// for the provided duration.
+func CreatePIT(
+	ctx context.Context,
+	client *opensearch.Client,
+	keepAlive time.Duration,
+	indices ...string,
+) (*PointInTime, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("point in time requires at least one index")
+	}
+
+	req := opensearchapi.PointInTimeCreateReq{
+		Indices: indices,
+		Params: opensearchapi.PointInTimeCreateParams{
+			KeepAlive: keepAlive,
+		},
+	}
+
+	var res opensearchapi.PointInTimeCreateResp
+	if err := execute(ctx, client, req, &res); err != nil {
+		return nil, fmt.Errorf("create point in time request failed: %w", err)
+	}
+
+	return &PointInTime{
+		ID:        res.PitID,
+		KeepAlive: keepAlive,
+	}, nil
+}
+
+// Delete closes the point in time, releasing the resources it holds.
+func (pit *PointInTime) Delete(ctx context.Context, client *opensearch.Client) error {
+	req := opensearchapi.PointInTimeDeleteReq{
+		PitID: []string{pit.ID},
+	}
+
+	var res opensearchapi.PointInTimeDeleteResp
+	if err := execute(ctx, client, req, &res); err != nil {
+		return fmt.Errorf("delete point in time request failed: %w", err)
+	}
+
+	return nil
+}
+
+// formatKeepAlive returns the representation of a keep-alive duration as
+// known to OpenSearch.
+func formatKeepAlive(dur time.Duration) string {
+	if dur%time.Second == 0 {
+		return fmt.Sprintf("%ds", int64(dur/time.Second))
+	}
+	return fmt.Sprintf("%dms", dur.Milliseconds())
+}
+
+//----------------------------------------------------------------------------//
+
+// PITIterator pages through all hits of a search request using a point in
+// time and "search_after", decoding the source of each hit into T. The point
+// in time is created when the first page is fetched, and deleted once all hits
+// have been returned, an error occurs or the context is cancelled.
+//
+//	it := osquery.IteratePIT[Doc](req, client, time.Minute, &osquery.SearchOptions{
+//	    Indices: []string{"logs"},
+//	})
+//	defer it.Close(ctx)
+//	for it.Next(ctx) {
+//	    doc := it.Hit().Source
+//	    // ...
+//	}
+//	if err := it.Err(); err != nil {
+//	    // ...
+//	}
+type PITIterator[T any] struct {
+	req        *SearchRequest
+	client     *opensearch.Client
+	options    *SearchOptions
+	keepAlive  time.Duration
+	tiebreaker SortOption
+
+	pit   *PointInTime
+	after []interface{}
+	hits  []Hit[T]
+	hit   Hit[T]
+	done  bool
+	err   error
+}
+
+// IteratePIT returns an iterator over all hits of the provided search
+// request. The point in time is created over the indices of the provided
+// options, which are not used for the searches themselves. The request is not
+// modified; every page is fetched using a copy of it, with the point in time,
+// a tiebreaker sort and the sort values of the previous page's last hit, and
+// without its from offset. A request without a size, or with a size of zero,
+// is fetched in pages of defaultPageSize hits. Pages whose hits have no sort
+// values, which happens when the request is not sorted and the tiebreaker is
+// nil, make the iterator fail rather than fetch the first page again.
+func IteratePIT[T any](
+	req *SearchRequest,
+	client *opensearch.Client,
+	keepAlive time.Duration,
+	options *SearchOptions,
+) *PITIterator[T] {
+	return &PITIterator[T]{
+		req:        req,
+		client:     client,
+		options:    options,
+		keepAlive:  keepAlive,
+		tiebreaker: FieldSort("_shard_doc"),
+	}
+}
+
+// Tiebreaker sets the sort option appended to the request's sort to ensure
+// every hit has unique sort values. The default is to sort on "_shard_doc".
+// The tiebreaker is not added if the request already sorts on its field.
+func (it *PITIterator[T]) Tiebreaker(sort SortOption) *PITIterator[T] {
+	it.tiebreaker = sort
+	return it
+}
+
+// Next advances the iterator to the next hit, fetching the next page of hits
+// if necessary. It returns false when all hits have been returned or an error
+// occurred, which is then available through Err.
+func (it *PITIterator[T]) Next(ctx context.Context) bool {
+	for len(it.hits) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch(ctx)
+	}
+
+	it.hit = it.hits[0]
+	it.hits = it.hits[1:]
+	return true
+}
+
+// fetch executes the search request for the next page of hits.
+func (it *PITIterator[T]) fetch(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		it.fail(ctx, err)
+		return
+	}
+
+	if it.pit == nil {
+		var indices []string
+		if it.options != nil {
+			indices = it.options.Indices
+		}
+		pit, err := CreatePIT(ctx, it.client, it.keepAlive, indices...)
+		if err != nil {
+			it.err = err
+			return
+		}
+		it.pit = pit
+	}
+
+	// searches against a point in time must not target any index
+	var options *SearchOptions
+	if it.options != nil {
+		opts := *it.options
+		opts.Indices = nil
+		options = &opts
+	}
+
+	res, err := RunInto[T](ctx, it.page(), it.client, options)
+	if err != nil {
+		it.fail(ctx, err)
+		return
+	}
+	if res.PitID != "" {
+		it.pit.ID = res.PitID
+	}
+
+	it.hits = res.Hits.Hits
+	if len(it.hits) == 0 || len(it.hits) < it.pageSize() {
+		it.finish(ctx)
+		return
+	}
+	it.after = it.hits[len(it.hits)-1].Sort
+	if len(it.after) == 0 {
+		// the next page would be the first one again
+		it.fail(ctx, errors.New("point in time search returned hits without sort values; sort the request or set a tiebreaker"))
+	}
+}
+
+// page returns a copy of the search request for the next page of hits.
+func (it *PITIterator[T]) page() *SearchRequest {
+	page := *it.req
+	page.pit = &pitParams{id: it.pit.ID, keepAlive: it.keepAlive}
+	page.searchAfter = it.after
+	// pages are selected by search_after only
+	page.from = nil
+	if page.size == nil || *page.size == 0 {
+		size := uint64(defaultPageSize)
+		page.size = &size
+	}
+	page.sort = append([]SortOption(nil), it.req.sort...)
+	if it.tiebreaker != nil && !sortsOn(page.sort, it.tiebreaker) {
+		page.sort = append(page.sort, it.tiebreaker)
+	}
+	return &page
+}
+
+// defaultPageSize is the number of hits per page of requests that do not set
+// a size, which is also the default size of OpenSearch.
+const defaultPageSize = 10
+
+// pageSize returns the number of hits requested per page.
+func (it *PITIterator[T]) pageSize() int {
+	if it.req.size != nil && *it.req.size > 0 {
+		return int(*it.req.size)
+	}
+	return defaultPageSize
+}
+
+// finish marks the iterator as done and deletes the point in time.
+func (it *PITIterator[T]) finish(ctx context.Context) {
+	it.done = true
+	if err := it.release(ctx); err != nil && it.err == nil {
+		it.err = err
+	}
+}
+
+// fail records the provided error and deletes the point in time. Errors
+// deleting the point in time are ignored in favor of the original one.
+func (it *PITIterator[T]) fail(ctx context.Context, err error) {
+	it.err = err
+	_ = it.release(ctx)
+}
+
+// release deletes the point in time if it is still open. The point in time is
+// deleted even if the provided context has been cancelled.
+func (it *PITIterator[T]) release(ctx context.Context) error {
+	if it.pit == nil {
+		return nil
+	}
+	pit := it.pit
+	it.pit = nil
+	return pit.Delete(context.WithoutCancel(ctx), it.client)
+}
+
+// Hit returns the current hit.
+func (it *PITIterator[T]) Hit() Hit[T] {
+	return it.hit
+}
+
+// PIT returns the point in time used by the iterator, or nil if it has not
+// been created yet or has already been deleted.
+func (it *PITIterator[T]) PIT() *PointInTime {
+	return it.pit
+}
+
+// Err returns the first error encountered by the iterator, if any.
+func (it *PITIterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and deletes the point in time if it is still
+// open. It is safe to call Close multiple times, and after the iteration has
+// finished.
+func (it *PITIterator[T]) Close(ctx context.Context) error {
+	it.done = true
+	it.hits = nil
+	return it.release(ctx)
+}
+
+// sortsOn returns whether the provided sort options already include a sort on
+// the field of the provided option.
+func sortsOn(sort []SortOption, opt SortOption) bool {
+	for field := range opt.Map() {
+		for _, s := range sort {
+			if _, ok := s.Map()[field]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+const testPITCreateResponse = `{
+	"pit_id": "pit-1",
+	"_shards": {"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+	"creation_time": 1700000000000
+}`
+
+const testPITDeleteResponse = `{"pits": [{"pit_id": "pit-2", "successful": true}]}`
+
+func TestSearchRequestPIT(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"search with pit",
+			Search().
+				Size(100).
+				PIT("pit-1", time.Minute).
+				SearchAfter(10, "1"),
+			map[string]interface{}{
+				"size":         100,
+				"search_after": []interface{}{10, "1"},
+				"pit": map[string]interface{}{
+					"id":         "pit-1",
+					"keep_alive": "60s",
+				},
+			},
+		},
+	})
+}
+
+func TestPITIterator(t *testing.T) {
+	client, requests := newTestClient(t,
+		testPITCreateResponse,
+		`{"pit_id": "pit-2", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}, "sort": [1, 10]},
+			{"_id": "2", "_source": {"title": "two"}, "sort": [2, 11]}
+		]}}`,
+		`{"pit_id": "pit-2", "hits": {"hits": [
+			{"_id": "3", "_source": {"title": "three"}, "sort": [3, 12]}
+		]}}`,
+		testPITDeleteResponse,
+	)
+
+	ctx := context.Background()
+	req := Query(MatchAll()).Size(2).Sort(FieldSort("views"))
+	it := IteratePIT[testDoc](req, client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+	})
+
+	var titles []string
+	for it.Next(ctx) {
+		titles = append(titles, it.Hit().Source.Title)
+	}
+	assert.Nil(t, it.Err())
+	assert.DeepEqual(t, []string{"one", "two", "three"}, titles)
+	assert.True(t, it.PIT() == nil)
+	assert.Nil(t, it.Close(ctx))
+
+	assert.Equal(t, 4, len(*requests))
+	assert.Equal(t, "/posts/_search/point_in_time", (*requests)[0].path)
+	assert.Equal(t, "keep_alive=60000ms", (*requests)[0].query)
+	assert.Equal(t, "/_search", (*requests)[1].path)
+	assert.Equal(t,
+		`{"pit":{"id":"pit-1","keep_alive":"60s"},"query":{"match_all":{}},"size":2,"sort":[{"views":{}},{"_shard_doc":{}}]}`,
+		(*requests)[1].body,
+	)
+	assert.Equal(t,
+		`{"pit":{"id":"pit-2","keep_alive":"60s"},"query":{"match_all":{}},"search_after":[2,11],"size":2,"sort":[{"views":{}},{"_shard_doc":{}}]}`,
+		(*requests)[2].body,
+	)
+	assert.Equal(t, "DELETE", (*requests)[3].method)
+	assert.Equal(t, `{"pit_id":["pit-2"]}`, (*requests)[3].body)
+
+	// the request itself is left untouched
+	assert.Equal(t, 1, len(req.sort))
+	assert.True(t, req.pit == nil)
+}
+
+func TestPITIteratorCancel(t *testing.T) {
+	client, requests := newTestClient(t,
+		testPITCreateResponse,
+		`{"pit_id": "pit-2", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}, "sort": [1]}
+		]}}`,
+		testPITDeleteResponse,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := IteratePIT[testDoc](Search().Size(1), client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+	})
+
+	assert.True(t, it.Next(ctx))
+	cancel()
+	assert.False(t, it.Next(ctx))
+	assert.Equal(t, context.Canceled, it.Err())
+
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t, "DELETE", (*requests)[2].method)
+	assert.Equal(t, `{"pit_id":["pit-2"]}`, (*requests)[2].body)
+}
+
+func TestPITIteratorError(t *testing.T) {
+	client, requests := newTestClient(t,
+		testPITCreateResponse,
+		`{"error": {"type": "search_phase_execution_exception", "reason": "all shards failed"}, "status": 500}`,
+		testPITDeleteResponse,
+	)
+
+	it := IteratePIT[testDoc](Search(), client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+	})
+
+	assert.False(t, it.Next(context.Background()))
+	assert.NotNil(t, it.Err())
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t, "DELETE", (*requests)[2].method)
+}
+
+func TestPITIteratorZeroSize(t *testing.T) {
+	client, requests := newTestClient(t,
+		testPITCreateResponse,
+		`{"pit_id": "pit-2", "hits": {"hits": []}}`,
+		testPITDeleteResponse,
+	)
+
+	it := IteratePIT[testDoc](Search().Size(0), client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+	})
+
+	assert.False(t, it.Next(context.Background()))
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t,
+		`{"pit":{"id":"pit-1","keep_alive":"60s"},"size":10,"sort":[{"_shard_doc":{}}]}`,
+		(*requests)[1].body,
+	)
+	assert.Equal(t, "DELETE", (*requests)[2].method)
+}
+
+func TestPITIteratorWithoutSortValues(t *testing.T) {
+	client, requests := newTestClient(t,
+		testPITCreateResponse,
+		`{"pit_id": "pit-1", "hits": {"hits": [{"_id": "1", "_source": {"title": "a"}}]}}`,
+		testPITDeleteResponse,
+	)
+
+	it := IteratePIT[testDoc](Search().Size(1).From(5), client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+	}).Tiebreaker(nil)
+
+	ctx := context.Background()
+	assert.True(t, it.Next(ctx))
+	assert.False(t, it.Next(ctx))
+	assert.NotNil(t, it.Err())
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t, `{"pit":{"id":"pit-1","keep_alive":"60s"},"size":1}`, (*requests)[1].body)
+	assert.Equal(t, "DELETE", (*requests)[2].method)
+}
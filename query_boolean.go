@@ -4,7 +4,11 @@
 
 package osquery
 
-import "github.com/fatih/structs"
+import (
+	"io"
+
+	"github.com/fatih/structs"
+)
 
 // BoolQuery represents a compound query of type "bool", as described in
 // https://opensearch.org/docs/latest/query-dsl/compound/bool/
@@ -120,3 +124,63 @@ func (q *BoolQuery) Map() map[string]interface{} {
 		"bool": structs.Map(data),
 	}
 }
+
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *BoolQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *BoolQuery) encodeJSON(e *encoder) {
+	clause := func(key string, queries []Mappable) {
+		if len(queries) == 0 {
+			return
+		}
+		e.key(key)
+		e.openArray()
+		for _, query := range queries {
+			e.mappable(query)
+		}
+		e.closeArray()
+	}
+
+	e.openObject()
+	e.key("bool")
+	e.openObject()
+	if q.name != "" {
+		e.key("_name")
+		e.str(q.name)
+	}
+	if q.boost != 0 {
+		e.key("boost")
+		e.float(float64(q.boost), 32)
+	}
+	clause("filter", q.filter)
+	if q.minimumShouldMatch != 0 {
+		e.key("minimum_should_match")
+		e.int(int64(q.minimumShouldMatch))
+	}
+	clause("must", q.must)
+	clause("must_not", q.mustNot)
+	clause("should", q.should)
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *BoolQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *BoolQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "bool")
+	if len(q.must)+len(q.filter)+len(q.mustNot)+len(q.should) == 0 {
+		errs.add(path, "bool query has no clauses")
+	}
+	validateQueries(q.must, path+".must", errs)
+	validateQueries(q.filter, path+".filter", errs)
+	validateQueries(q.mustNot, path+".must_not", errs)
+	validateQueries(q.should, path+".should", errs)
+}
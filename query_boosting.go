@@ -49,3 +49,24 @@ func (q *BoostingQuery) Map() map[string]interface{} {
 		},
 	}
 }
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *BoostingQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *BoostingQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "boosting")
+	if q.Pos == nil {
+		errs.add(path, "positive query is required")
+	} else {
+		validateNode(q.Pos, path+".positive", errs)
+	}
+	if q.Neg == nil {
+		errs.add(path, "negative query is required")
+	} else {
+		validateNode(q.Neg, path+".negative", errs)
+	}
+}
@@ -47,3 +47,19 @@ func (q *ConstantScoreQuery) Map() map[string]interface{} {
 		}{q.filter.Map(), q.boost, q.name}),
 	}
 }
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *ConstantScoreQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *ConstantScoreQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "constant_score")
+	if q.filter == nil {
+		errs.add(path, "filter is required")
+		return
+	}
+	validateNode(q.filter, path+".filter", errs)
+}
@@ -41,3 +41,18 @@ func (q *DisMaxQuery) Map() map[string]interface{} {
 		}{inner, q.tieBreaker}),
 	}
 }
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *DisMaxQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *DisMaxQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "dis_max")
+	if len(q.queries) == 0 {
+		errs.add(path, "dis_max query has no queries")
+	}
+	validateQueries(q.queries, path+".queries", errs)
+}
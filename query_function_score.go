@@ -0,0 +1,436 @@
+package osquery
+
+import "fmt"
+
+// FunctionScoreQuery represents a compound query of type "function_score", as
+// described in
+// https://opensearch.org/docs/latest/query-dsl/compound/function-score/
+type FunctionScoreQuery struct {
+	query     Mappable
+	functions []*ScoreFunction
+	scoreMode FunctionScoreMode
+	boostMode FunctionBoostMode
+	maxBoost  *float32
+	minScore  *float32
+	boost     float32
+	name      string
+}
+
+// FunctionScore creates a new query of type "function_score" with the provided
+// query. Functions modifying the score of the documents it returns are added
+// with Functions.
+func FunctionScore(query Mappable) *FunctionScoreQuery {
+	return &FunctionScoreQuery{
+		query: query,
+	}
+}
+
+// Functions adds one or more score functions to the query. Functions can be
+// called multiple times, functions will be appended to existing ones.
+func (q *FunctionScoreQuery) Functions(functions ...*ScoreFunction) *FunctionScoreQuery {
+	q.functions = append(q.functions, functions...)
+	return q
+}
+
+// ScoreMode sets how the scores computed by the functions are combined.
+func (q *FunctionScoreQuery) ScoreMode(mode FunctionScoreMode) *FunctionScoreQuery {
+	q.scoreMode = mode
+	return q
+}
+
+// BoostMode sets how the combined function score is combined with the score of
+// the query.
+func (q *FunctionScoreQuery) BoostMode(mode FunctionBoostMode) *FunctionScoreQuery {
+	q.boostMode = mode
+	return q
+}
+
+// MaxBoost sets the maximum value of the combined function score.
+func (q *FunctionScoreQuery) MaxBoost(b float32) *FunctionScoreQuery {
+	q.maxBoost = &b
+	return q
+}
+
+// MinScore sets the minimum score documents must have to be returned.
+func (q *FunctionScoreQuery) MinScore(s float32) *FunctionScoreQuery {
+	q.minScore = &s
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *FunctionScoreQuery) Boost(b float32) *FunctionScoreQuery {
+	q.boost = b
+	return q
+}
+
+// Name sets the name of the query that is returned in matched_queries in response
+// if document matches the query.
+func (q *FunctionScoreQuery) Name(name string) *FunctionScoreQuery {
+	q.name = name
+	return q
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *FunctionScoreQuery) Map() map[string]interface{} {
+	innerMap := make(map[string]interface{})
+	if q.query != nil {
+		innerMap["query"] = q.query.Map()
+	}
+	if len(q.functions) > 0 {
+		functions := make([]map[string]interface{}, len(q.functions))
+		for i, fn := range q.functions {
+			functions[i] = fn.Map()
+		}
+		innerMap["functions"] = functions
+	}
+	if q.scoreMode != "" {
+		innerMap["score_mode"] = q.scoreMode
+	}
+	if q.boostMode != "" {
+		innerMap["boost_mode"] = q.boostMode
+	}
+	if q.maxBoost != nil {
+		innerMap["max_boost"] = *q.maxBoost
+	}
+	if q.minScore != nil {
+		innerMap["min_score"] = *q.minScore
+	}
+	if q.boost != 0 {
+		innerMap["boost"] = q.boost
+	}
+	if q.name != "" {
+		innerMap["_name"] = q.name
+	}
+
+	return map[string]interface{}{
+		"function_score": innerMap,
+	}
+}
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *FunctionScoreQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *FunctionScoreQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "function_score")
+	if q.query != nil {
+		validateNode(q.query, path+".query", errs)
+	}
+	for i, fn := range q.functions {
+		fnPath := fmt.Sprintf("%s.functions[%d]", path, i)
+		switch {
+		case fn.fType == "script_score":
+			validateScript(fn.script, fnPath+".script_score", errs)
+		case fn.isType(decayFunctions...):
+			if fn.field == "" {
+				errs.add(fnPath+"."+fn.fType, "field is required")
+			}
+			if fn.params["scale"] == nil {
+				errs.add(fnPath+"."+fn.fType, "scale is required")
+			}
+		}
+		if fn.filter != nil {
+			validateNode(fn.filter, fnPath+".filter", errs)
+		}
+	}
+}
+
+// FunctionScoreMode is an enumeration type representing supported values for a
+// function score query's "score_mode" parameter.
+type FunctionScoreMode string
+
+const (
+	// FunctionScoreModeMultiply multiplies the function scores (the default).
+	FunctionScoreModeMultiply FunctionScoreMode = "multiply"
+
+	// FunctionScoreModeSum sums the function scores.
+	FunctionScoreModeSum FunctionScoreMode = "sum"
+
+	// FunctionScoreModeAvg averages the function scores.
+	FunctionScoreModeAvg FunctionScoreMode = "avg"
+
+	// FunctionScoreModeFirst uses the score of the first function with a
+	// matching filter.
+	FunctionScoreModeFirst FunctionScoreMode = "first"
+
+	// FunctionScoreModeMax uses the maximum function score.
+	FunctionScoreModeMax FunctionScoreMode = "max"
+
+	// FunctionScoreModeMin uses the minimum function score.
+	FunctionScoreModeMin FunctionScoreMode = "min"
+)
+
+// FunctionBoostMode is an enumeration type representing supported values for
+// a function score query's "boost_mode" parameter.
+type FunctionBoostMode string
+
+const (
+	// BoostModeMultiply multiplies the query score by the function score (the
+	// default).
+	BoostModeMultiply FunctionBoostMode = "multiply"
+
+	// BoostModeReplace ignores the query score and uses the function score.
+	BoostModeReplace FunctionBoostMode = "replace"
+
+	// BoostModeSum adds the function score to the query score.
+	BoostModeSum FunctionBoostMode = "sum"
+
+	// BoostModeAvg averages the query score and the function score.
+	BoostModeAvg FunctionBoostMode = "avg"
+
+	// BoostModeMax uses the maximum of the query score and the function score.
+	BoostModeMax FunctionBoostMode = "max"
+
+	// BoostModeMin uses the minimum of the query score and the function score.
+	BoostModeMin FunctionBoostMode = "min"
+)
+
+//----------------------------------------------------------------------------//
+
+// ScoreFunction represents a single function of a function score query. A
+// function may be restricted to the documents matching a filter, and may carry
+// a weight. Functions are created with WeightFunction, RandomScoreFunction,
+// FieldValueFactorFunction, ScriptScoreFunction, GaussFunction,
+// LinearFunction and ExpFunction. Setters specific to some types of functions
+// are ignored by the others.
+type ScoreFunction struct {
+	fType  string
+	params map[string]interface{}
+	filter Mappable
+	weight *float32
+
+	// script_score functions only
+	script *ScriptField
+
+	// decay functions only
+	field          string
+	multiValueMode Mode
+}
+
+// WeightFunction creates a score function that multiplies the score by the
+// provided weight.
+func WeightFunction(weight float32) *ScoreFunction {
+	fn := &ScoreFunction{
+		params: make(map[string]interface{}),
+	}
+	return fn.Weight(weight)
+}
+
+// RandomScoreFunction creates a score function of type "random_score", which
+// generates scores uniformly distributed between 0 and 1.
+func RandomScoreFunction() *ScoreFunction {
+	return &ScoreFunction{
+		fType:  "random_score",
+		params: make(map[string]interface{}),
+	}
+}
+
+// FieldValueFactorFunction creates a score function of type
+// "field_value_factor", which computes the score from the value of the
+// provided field.
+func FieldValueFactorFunction(field string) *ScoreFunction {
+	return &ScoreFunction{
+		fType: "field_value_factor",
+		params: map[string]interface{}{
+			"field": field,
+		},
+	}
+}
+
+// ScriptScoreFunction creates a score function of type "script_score", which
+// computes the score using the provided script.
+func ScriptScoreFunction(script *ScriptField) *ScoreFunction {
+	fn := &ScoreFunction{
+		fType:  "script_score",
+		params: make(map[string]interface{}),
+		script: script,
+	}
+	if script != nil {
+		fn.params["script"] = scriptMap(script)
+	}
+	return fn
+}
+
+// GaussFunction creates a decay function of type "gauss" on the provided
+// field, decaying from the provided origin over the provided scale.
+func GaussFunction(field string, origin, scale interface{}) *ScoreFunction {
+	return newDecayFunction("gauss", field, origin, scale)
+}
+
+// LinearFunction creates a decay function of type "linear" on the provided
+// field, decaying from the provided origin over the provided scale.
+func LinearFunction(field string, origin, scale interface{}) *ScoreFunction {
+	return newDecayFunction("linear", field, origin, scale)
+}
+
+// ExpFunction creates a decay function of type "exp" on the provided field,
+// decaying from the provided origin over the provided scale.
+func ExpFunction(field string, origin, scale interface{}) *ScoreFunction {
+	return newDecayFunction("exp", field, origin, scale)
+}
+
+func newDecayFunction(fType, field string, origin, scale interface{}) *ScoreFunction {
+	params := map[string]interface{}{
+		"scale": scale,
+	}
+	if origin != nil {
+		params["origin"] = origin
+	}
+
+	return &ScoreFunction{
+		fType:  fType,
+		field:  field,
+		params: params,
+	}
+}
+
+// Filter restricts the function to documents matching the provided query.
+func (fn *ScoreFunction) Filter(filter Mappable) *ScoreFunction {
+	fn.filter = filter
+	return fn
+}
+
+// Weight sets a weight to multiply the score of the function by.
+func (fn *ScoreFunction) Weight(weight float32) *ScoreFunction {
+	fn.weight = &weight
+	return fn
+}
+
+// Seed sets the seed of a "random_score" function, making scores
+// reproducible.
+func (fn *ScoreFunction) Seed(seed interface{}) *ScoreFunction {
+	return fn.set("seed", seed, "random_score")
+}
+
+// Field sets the field a "random_score" function reads its seed from.
+func (fn *ScoreFunction) Field(field string) *ScoreFunction {
+	return fn.set("field", field, "random_score")
+}
+
+// Factor sets the factor to multiply the value of a "field_value_factor"
+// function's field by.
+func (fn *ScoreFunction) Factor(factor float32) *ScoreFunction {
+	return fn.set("factor", factor, "field_value_factor")
+}
+
+// Modifier sets the modifier applied to the value of a "field_value_factor"
+// function's field.
+func (fn *ScoreFunction) Modifier(modifier FieldValueFactorModifier) *ScoreFunction {
+	return fn.set("modifier", modifier, "field_value_factor")
+}
+
+// Missing sets the value to use for documents missing a value for a
+// "field_value_factor" function's field.
+func (fn *ScoreFunction) Missing(val float64) *ScoreFunction {
+	return fn.set("missing", val, "field_value_factor")
+}
+
+// Offset sets the distance from the origin within which a decay function
+// doesn't decay.
+func (fn *ScoreFunction) Offset(offset interface{}) *ScoreFunction {
+	return fn.set("offset", offset, decayFunctions...)
+}
+
+// Decay sets the score a decay function assigns at a distance of scale from
+// the origin (0.5 by default).
+func (fn *ScoreFunction) Decay(decay float64) *ScoreFunction {
+	return fn.set("decay", decay, decayFunctions...)
+}
+
+// MultiValueMode sets which value of a multi-valued field a decay function
+// uses to compute the distance.
+func (fn *ScoreFunction) MultiValueMode(mode Mode) *ScoreFunction {
+	if fn.isType(decayFunctions...) {
+		fn.multiValueMode = mode
+	}
+	return fn
+}
+
+// decayFunctions are the types of decay functions.
+var decayFunctions = []string{"gauss", "linear", "exp"}
+
+// set sets a parameter of the function if it is of one of the provided types.
+func (fn *ScoreFunction) set(key string, val interface{}, fTypes ...string) *ScoreFunction {
+	if fn.isType(fTypes...) {
+		fn.params[key] = val
+	}
+	return fn
+}
+
+func (fn *ScoreFunction) isType(fTypes ...string) bool {
+	for _, fType := range fTypes {
+		if fn.fType == fType {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (fn *ScoreFunction) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if fn.filter != nil {
+		m["filter"] = fn.filter.Map()
+	}
+	if fn.weight != nil {
+		m["weight"] = *fn.weight
+	}
+
+	switch {
+	case fn.fType == "":
+		// weight-only function
+	case fn.field != "":
+		inner := map[string]interface{}{
+			fn.field: fn.params,
+		}
+		if fn.multiValueMode != "" {
+			inner["multi_value_mode"] = fn.multiValueMode
+		}
+		m[fn.fType] = inner
+	default:
+		m[fn.fType] = fn.params
+	}
+
+	return m
+}
+
+// FieldValueFactorModifier is an enumeration type representing supported
+// values for a field_value_factor function's "modifier" parameter.
+type FieldValueFactorModifier string
+
+const (
+	// ModifierNone applies no modifier (the default).
+	ModifierNone FieldValueFactorModifier = "none"
+
+	// ModifierLog takes the common logarithm of the value.
+	ModifierLog FieldValueFactorModifier = "log"
+
+	// ModifierLog1p adds 1 to the value and takes the common logarithm.
+	ModifierLog1p FieldValueFactorModifier = "log1p"
+
+	// ModifierLog2p adds 2 to the value and takes the common logarithm.
+	ModifierLog2p FieldValueFactorModifier = "log2p"
+
+	// ModifierLn takes the natural logarithm of the value.
+	ModifierLn FieldValueFactorModifier = "ln"
+
+	// ModifierLn1p adds 1 to the value and takes the natural logarithm.
+	ModifierLn1p FieldValueFactorModifier = "ln1p"
+
+	// ModifierLn2p adds 2 to the value and takes the natural logarithm.
+	ModifierLn2p FieldValueFactorModifier = "ln2p"
+
+	// ModifierSquare squares the value.
+	ModifierSquare FieldValueFactorModifier = "square"
+
+	// ModifierSqrt takes the square root of the value.
+	ModifierSqrt FieldValueFactorModifier = "sqrt"
+
+	// ModifierReciprocal takes the reciprocal of the value.
+	ModifierReciprocal FieldValueFactorModifier = "reciprocal"
+)
@@ -0,0 +1,187 @@
+package osquery
+
+import (
+	"testing"
+)
+
+func TestFunctionScore(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"function_score with weight and random_score",
+			FunctionScore(MatchAll()).
+				Functions(
+					WeightFunction(23).Filter(Term("type", "post")),
+					RandomScoreFunction().Seed(10).Field("_seq_no"),
+				).
+				ScoreMode(FunctionScoreModeSum).
+				BoostMode(BoostModeReplace).
+				MaxBoost(42).
+				MinScore(1.5).
+				Boost(2),
+			map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query": map[string]interface{}{
+						"match_all": map[string]interface{}{},
+					},
+					"functions": []map[string]interface{}{
+						{
+							"filter": map[string]interface{}{
+								"term": map[string]interface{}{
+									"type": map[string]interface{}{
+										"value": "post",
+									},
+								},
+							},
+							"weight": 23,
+						},
+						{
+							"random_score": map[string]interface{}{
+								"seed":  10,
+								"field": "_seq_no",
+							},
+						},
+					},
+					"score_mode": "sum",
+					"boost_mode": "replace",
+					"max_boost":  42,
+					"min_score":  1.5,
+					"boost":      2,
+				},
+			},
+		},
+		{
+			"function_score with field_value_factor and script_score",
+			FunctionScore(Term("user", "kimchy")).
+				Functions(
+					FieldValueFactorFunction("likes").
+						Factor(1.2).
+						Modifier(ModifierSqrt).
+						Missing(1),
+					ScriptScoreFunction(Script("").Source("Math.log(2 + doc['likes'].value)")).
+						Weight(0.5),
+				),
+			map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query": map[string]interface{}{
+						"term": map[string]interface{}{
+							"user": map[string]interface{}{
+								"value": "kimchy",
+							},
+						},
+					},
+					"functions": []map[string]interface{}{
+						{
+							"field_value_factor": map[string]interface{}{
+								"field":    "likes",
+								"factor":   1.2,
+								"modifier": "sqrt",
+								"missing":  1,
+							},
+						},
+						{
+							"script_score": map[string]interface{}{
+								"script": map[string]interface{}{
+									"source": "Math.log(2 + doc['likes'].value)",
+								},
+							},
+							"weight": 0.5,
+						},
+					},
+				},
+			},
+		},
+		{
+			"function_score with decay functions",
+			FunctionScore(nil).
+				Functions(
+					GaussFunction("date", "2013-09-17", "10d").
+						Offset("5d").
+						Decay(0.5).
+						MultiValueMode(SortModeAvg),
+					LinearFunction("price", 0, 20),
+					ExpFunction("location", "11,12", "2km").
+						Filter(Exists("location")),
+				).
+				ScoreMode(FunctionScoreModeMultiply),
+			map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"functions": []map[string]interface{}{
+						{
+							"gauss": map[string]interface{}{
+								"date": map[string]interface{}{
+									"origin": "2013-09-17",
+									"scale":  "10d",
+									"offset": "5d",
+									"decay":  0.5,
+								},
+								"multi_value_mode": "avg",
+							},
+						},
+						{
+							"linear": map[string]interface{}{
+								"price": map[string]interface{}{
+									"origin": 0,
+									"scale":  20,
+								},
+							},
+						},
+						{
+							"filter": map[string]interface{}{
+								"exists": map[string]interface{}{
+									"field": "location",
+								},
+							},
+							"exp": map[string]interface{}{
+								"location": map[string]interface{}{
+									"origin": "11,12",
+									"scale":  "2km",
+								},
+							},
+						},
+					},
+					"score_mode": "multiply",
+				},
+			},
+		},
+	})
+}
+
+func TestScoreFunctionSetters(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"setters of other function types are ignored",
+			FunctionScore(MatchAll()).Functions(
+				WeightFunction(2).Field("user").Seed(1).Factor(2).Decay(0.5).MultiValueMode(SortModeMin),
+				RandomScoreFunction().Factor(2).Offset("1d"),
+				FieldValueFactorFunction("likes").Seed(3).Missing(1),
+				GaussFunction("date", "now", "10d").Modifier(ModifierLog).Decay(0.3),
+			),
+			map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query": map[string]interface{}{
+						"match_all": map[string]interface{}{},
+					},
+					"functions": []map[string]interface{}{
+						{"weight": 2},
+						{"random_score": map[string]interface{}{}},
+						{
+							"field_value_factor": map[string]interface{}{
+								"field":   "likes",
+								"missing": 1,
+							},
+						},
+						{
+							"gauss": map[string]interface{}{
+								"date": map[string]interface{}{
+									"origin": "now",
+									"scale":  "10d",
+									"decay":  0.3,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
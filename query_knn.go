@@ -1,6 +1,10 @@
 package osquery
 
-import "github.com/fatih/structs"
+import (
+	"io"
+
+	"github.com/fatih/structs"
+)
 
 // KNNQuery represents a k-nearest-neighbors query for OpenSearch, as described in
 // https://opensearch.org/docs/latest/query-dsl/specialized/k-nn/
@@ -93,3 +97,80 @@ func (q *KNNQuery) Map() map[string]interface{} {
 		},
 	}
 }
+
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *KNNQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *KNNQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("knn")
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.expandNestedDocs != nil {
+		e.key("expand_nested_docs")
+		e.boolean(*q.expandNestedDocs)
+	}
+	if q.filter != nil {
+		e.key("filter")
+		e.value(q.filter)
+	}
+	if q.k != nil {
+		e.key("k")
+		e.int(int64(*q.k))
+	}
+	if q.maxDistance != nil {
+		e.key("max_distance")
+		e.float(*q.maxDistance, 64)
+	}
+	if q.methodParameters != nil {
+		e.key("method_parameters")
+		e.value(q.methodParameters)
+	}
+	if q.minScore != nil {
+		e.key("min_score")
+		e.float(*q.minScore, 64)
+	}
+	if q.rescore != nil {
+		e.key("rescore")
+		e.value(q.rescore)
+	}
+	e.key("vector")
+	e.floats(q.vector)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks the query for problems that would make OpenSearch reject
+// it. It returns a ValidationErrors value listing all the problems found, or
+// nil.
+func (q *KNNQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *KNNQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "knn."+q.field)
+	if len(q.vector) == 0 {
+		errs.add(path, "vector is required")
+	}
+
+	// k-NN and radial searches are mutually exclusive
+	set := 0
+	for _, ok := range []bool{q.k != nil, q.maxDistance != nil, q.minScore != nil} {
+		if ok {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		errs.add(path, "one of k, max_distance or min_score is required")
+	case set > 1:
+		errs.add(path, "only one of k, max_distance or min_score can be set")
+	case q.k != nil && *q.k <= 0:
+		errs.add(path, "k must be positive, got %d", *q.k)
+	}
+}
@@ -42,25 +42,26 @@ type MatchQuery struct {
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *MatchQuery) Map() map[string]interface{} {
-	var mType string
-	switch q.mType {
-	case TypeMatch:
-		mType = "match"
-	case TypeMatchBoolPrefix:
-		mType = "match_bool_prefix"
-	case TypeMatchPhrase:
-		mType = "match_phrase"
-	case TypeMatchPhrasePrefix:
-		mType = "match_phrase_prefix"
-	}
-
 	return map[string]interface{}{
-		mType: map[string]interface{}{
+		q.mType.apiName(): map[string]interface{}{
 			q.field: structs.Map(q.params),
 		},
 	}
 }
 
+// apiName returns the name of the query type in the OpenSearch DSL.
+func (t matchType) apiName() string {
+	switch t {
+	case TypeMatchBoolPrefix:
+		return "match_bool_prefix"
+	case TypeMatchPhrase:
+		return "match_phrase"
+	case TypeMatchPhrasePrefix:
+		return "match_phrase_prefix"
+	}
+	return "match"
+}
+
 type matchParams struct {
 	Qry                 interface{}   `structs:"query"`
 	Anl                 string        `structs:"analyzer,omitempty"`
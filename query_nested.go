@@ -2,7 +2,11 @@
 // Changes: Added nested query support
 package osquery
 
-import "github.com/fatih/structs"
+import (
+	"io"
+
+	"github.com/fatih/structs"
+)
 
 type ScoreModeType string
 
@@ -63,3 +67,48 @@ func (q *NestedQuery) Map() map[string]interface{} {
 		}{q.path, q.query.Map(), q.scoreMode, q.innerHits}),
 	}
 }
+
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *NestedQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *NestedQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("nested")
+	e.openObject()
+	if q.innerHits != nil {
+		e.key("inner_hits")
+		e.value(q.innerHits)
+	}
+	e.key("path")
+	e.str(q.path)
+	e.key("query")
+	e.mappable(q.query)
+	if q.scoreMode != "" {
+		e.key("score_mode")
+		e.str(q.scoreMode)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *NestedQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *NestedQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "nested")
+	if q.path == "" {
+		errs.add(path, "path is required")
+	}
+	if q.query == nil {
+		errs.add(path, "query is required")
+		return
+	}
+	validateNode(q.query, path+".query", errs)
+}
@@ -0,0 +1,445 @@
+package osquery
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// QueryStringQuery represents a query of type "query_string", as described in
+// https://opensearch.org/docs/latest/query-dsl/full-text/query-string/
+type QueryStringQuery struct {
+	params queryStringParams
+}
+
+// Map returns a map representation of the query; implementing the
+// Mappable interface.
+func (q *QueryStringQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"query_string": structs.Map(q.params),
+	}
+}
+
+type queryStringParams struct {
+	Qry                 string         `structs:"query"`
+	DefaultField        string         `structs:"default_field,omitempty"`
+	Fields              []string       `structs:"fields,omitempty"`
+	Type                MultiMatchType `structs:"type,string,omitempty"`
+	DefaultOp           MatchOperator  `structs:"default_operator,string,omitempty"`
+	Anl                 string         `structs:"analyzer,omitempty"`
+	QuoteAnl            string         `structs:"quote_analyzer,omitempty"`
+	QuoteSuffix         string         `structs:"quote_field_suffix,omitempty"`
+	PhraseSlp           uint16         `structs:"phrase_slop,omitempty"`
+	AllowLeading        *bool          `structs:"allow_leading_wildcard,omitempty"`
+	AnalyzeWild         *bool          `structs:"analyze_wildcard,omitempty"`
+	EnablePosInc        *bool          `structs:"enable_position_increments,omitempty"`
+	AutoGenerate        *bool          `structs:"auto_generate_synonyms_phrase_query,omitempty"`
+	Fuzz                string         `structs:"fuzziness,omitempty"`
+	FuzzyMaxExp         uint16         `structs:"fuzzy_max_expansions,omitempty"`
+	FuzzyPrefLen        uint16         `structs:"fuzzy_prefix_length,omitempty"`
+	FuzzyTranspositions *bool          `structs:"fuzzy_transpositions,omitempty"`
+	FuzzyRw             string         `structs:"fuzzy_rewrite,omitempty"`
+	Lent                *bool          `structs:"lenient,omitempty"`
+	MaxStates           uint32         `structs:"max_determinized_states,omitempty"`
+	MinMatch            string         `structs:"minimum_should_match,omitempty"`
+	Rewrite             string         `structs:"rewrite,omitempty"`
+	TZ                  string         `structs:"time_zone,omitempty"`
+	TieBrk              float32        `structs:"tie_breaker,omitempty"`
+	Boost               float32        `structs:"boost,omitempty"`
+	Name                string         `structs:"_name,omitempty"`
+}
+
+// QueryString creates a new query of type "query_string" with the provided
+// query string, written in the Lucene query syntax.
+func QueryString(query string) *QueryStringQuery {
+	return &QueryStringQuery{
+		params: queryStringParams{
+			Qry: query,
+		},
+	}
+}
+
+// Query sets the query string to parse and use for search.
+func (q *QueryStringQuery) Query(query string) *QueryStringQuery {
+	q.params.Qry = query
+	return q
+}
+
+// Name sets the name of the query that is returned in matched_queries in response
+// if document matches the query.
+func (q *QueryStringQuery) Name(n string) *QueryStringQuery {
+	q.params.Name = n
+	return q
+}
+
+// DefaultField sets the field to search when no field is specified in the
+// query string and Fields is not set.
+func (q *QueryStringQuery) DefaultField(f string) *QueryStringQuery {
+	q.params.DefaultField = f
+	return q
+}
+
+// Fields sets the fields used in the query. Fields may include a boost using
+// the "field^boost" notation, or use FieldWithBoost.
+func (q *QueryStringQuery) Fields(a ...string) *QueryStringQuery {
+	q.params.Fields = append(q.params.Fields, a...)
+	return q
+}
+
+// FieldWithBoost adds a field to the query with the provided boost.
+func (q *QueryStringQuery) FieldWithBoost(field string, boost float32) *QueryStringQuery {
+	return q.Fields(boostedField(field, boost))
+}
+
+// Type sets how the query matches and scores documents when multiple fields
+// are searched.
+func (q *QueryStringQuery) Type(t MultiMatchType) *QueryStringQuery {
+	q.params.Type = t
+	return q
+}
+
+// DefaultOperator sets the boolean logic used to interpret text in the query
+// string when no operator is specified.
+func (q *QueryStringQuery) DefaultOperator(op MatchOperator) *QueryStringQuery {
+	q.params.DefaultOp = op
+	return q
+}
+
+// Analyzer sets the analyzer used to convert the text in the query string into
+// tokens.
+func (q *QueryStringQuery) Analyzer(a string) *QueryStringQuery {
+	q.params.Anl = a
+	return q
+}
+
+// QuoteAnalyzer sets the analyzer used to convert quoted text in the query
+// string into tokens.
+func (q *QueryStringQuery) QuoteAnalyzer(a string) *QueryStringQuery {
+	q.params.QuoteAnl = a
+	return q
+}
+
+// QuoteFieldSuffix sets a suffix appended to the fields searched by quoted
+// text in the query string.
+func (q *QueryStringQuery) QuoteFieldSuffix(s string) *QueryStringQuery {
+	q.params.QuoteSuffix = s
+	return q
+}
+
+// PhraseSlop sets the maximum number of positions allowed between matching
+// tokens of phrases.
+func (q *QueryStringQuery) PhraseSlop(n uint16) *QueryStringQuery {
+	q.params.PhraseSlp = n
+	return q
+}
+
+// AllowLeadingWildcard sets whether "*" and "?" are allowed as the first
+// character of a term.
+func (q *QueryStringQuery) AllowLeadingWildcard(b bool) *QueryStringQuery {
+	q.params.AllowLeading = &b
+	return q
+}
+
+// AnalyzeWildcard sets whether wildcard and prefix queries are analyzed.
+func (q *QueryStringQuery) AnalyzeWildcard(b bool) *QueryStringQuery {
+	q.params.AnalyzeWild = &b
+	return q
+}
+
+// EnablePositionIncrements sets whether position increments are enabled in
+// queries constructed from the query string.
+func (q *QueryStringQuery) EnablePositionIncrements(b bool) *QueryStringQuery {
+	q.params.EnablePosInc = &b
+	return q
+}
+
+// AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
+// boolean.
+func (q *QueryStringQuery) AutoGenerateSynonymsPhraseQuery(b bool) *QueryStringQuery {
+	q.params.AutoGenerate = &b
+	return q
+}
+
+// Fuzziness set the maximum edit distance allowed for matching.
+func (q *QueryStringQuery) Fuzziness(f string) *QueryStringQuery {
+	q.params.Fuzz = f
+	return q
+}
+
+// FuzzyMaxExpansions sets the maximum number of terms to which fuzzy terms
+// will expand.
+func (q *QueryStringQuery) FuzzyMaxExpansions(e uint16) *QueryStringQuery {
+	q.params.FuzzyMaxExp = e
+	return q
+}
+
+// FuzzyPrefixLength sets the number of beginning characters left unchanged for
+// fuzzy matching.
+func (q *QueryStringQuery) FuzzyPrefixLength(l uint16) *QueryStringQuery {
+	q.params.FuzzyPrefLen = l
+	return q
+}
+
+// FuzzyTranspositions sets whether edits for fuzzy matching include transpositions
+// of two adjacent characters.
+func (q *QueryStringQuery) FuzzyTranspositions(b bool) *QueryStringQuery {
+	q.params.FuzzyTranspositions = &b
+	return q
+}
+
+// FuzzyRewrite sets the method used to rewrite fuzzy terms.
+func (q *QueryStringQuery) FuzzyRewrite(s string) *QueryStringQuery {
+	q.params.FuzzyRw = s
+	return q
+}
+
+// Lenient sets whether format-based errors should be ignored.
+func (q *QueryStringQuery) Lenient(b bool) *QueryStringQuery {
+	q.params.Lent = &b
+	return q
+}
+
+// MaxDeterminizedStates sets the maximum number of automaton states required
+// by regular expressions in the query string.
+func (q *QueryStringQuery) MaxDeterminizedStates(n uint32) *QueryStringQuery {
+	q.params.MaxStates = n
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses that must match for a
+// document to be returned.
+func (q *QueryStringQuery) MinimumShouldMatch(s string) *QueryStringQuery {
+	q.params.MinMatch = s
+	return q
+}
+
+// Rewrite sets the method used to rewrite multi-term queries.
+func (q *QueryStringQuery) Rewrite(s string) *QueryStringQuery {
+	q.params.Rewrite = s
+	return q
+}
+
+// TimeZone sets the time zone used to convert date values in the query string
+// to UTC.
+func (q *QueryStringQuery) TimeZone(tz string) *QueryStringQuery {
+	q.params.TZ = tz
+	return q
+}
+
+// TieBreaker sets the tie breaker value
+func (q *QueryStringQuery) TieBreaker(l float32) *QueryStringQuery {
+	q.params.TieBrk = l
+	return q
+}
+
+// Boost sets the boost value for the query.
+func (q *QueryStringQuery) Boost(l float32) *QueryStringQuery {
+	q.params.Boost = l
+	return q
+}
+
+//----------------------------------------------------------------------------//
+
+// SimpleQueryStringQuery represents a query of type "simple_query_string", as
+// described in
+// https://opensearch.org/docs/latest/query-dsl/full-text/simple-query-string/
+type SimpleQueryStringQuery struct {
+	params simpleQueryStringParams
+}
+
+// Map returns a map representation of the query; implementing the
+// Mappable interface.
+func (q *SimpleQueryStringQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"simple_query_string": structs.Map(q.params),
+	}
+}
+
+type simpleQueryStringParams struct {
+	Qry                 string        `structs:"query"`
+	Fields              []string      `structs:"fields,omitempty"`
+	DefaultOp           MatchOperator `structs:"default_operator,string,omitempty"`
+	Anl                 string        `structs:"analyzer,omitempty"`
+	Flgs                string        `structs:"flags,omitempty"`
+	QuoteSuffix         string        `structs:"quote_field_suffix,omitempty"`
+	AnalyzeWild         *bool         `structs:"analyze_wildcard,omitempty"`
+	AutoGenerate        *bool         `structs:"auto_generate_synonyms_phrase_query,omitempty"`
+	FuzzyMaxExp         uint16        `structs:"fuzzy_max_expansions,omitempty"`
+	FuzzyPrefLen        uint16        `structs:"fuzzy_prefix_length,omitempty"`
+	FuzzyTranspositions *bool         `structs:"fuzzy_transpositions,omitempty"`
+	Lent                *bool         `structs:"lenient,omitempty"`
+	MinMatch            string        `structs:"minimum_should_match,omitempty"`
+	Boost               float32       `structs:"boost,omitempty"`
+	Name                string        `structs:"_name,omitempty"`
+}
+
+// SimpleQueryString creates a new query of type "simple_query_string" with the
+// provided query string. Unlike QueryString, invalid syntax in the query
+// string is ignored rather than returning an error.
+func SimpleQueryString(query string) *SimpleQueryStringQuery {
+	return &SimpleQueryStringQuery{
+		params: simpleQueryStringParams{
+			Qry: query,
+		},
+	}
+}
+
+// Query sets the query string to parse and use for search.
+func (q *SimpleQueryStringQuery) Query(query string) *SimpleQueryStringQuery {
+	q.params.Qry = query
+	return q
+}
+
+// Name sets the name of the query that is returned in matched_queries in response
+// if document matches the query.
+func (q *SimpleQueryStringQuery) Name(n string) *SimpleQueryStringQuery {
+	q.params.Name = n
+	return q
+}
+
+// Fields sets the fields used in the query. Fields may include a boost using
+// the "field^boost" notation, or use FieldWithBoost.
+func (q *SimpleQueryStringQuery) Fields(a ...string) *SimpleQueryStringQuery {
+	q.params.Fields = append(q.params.Fields, a...)
+	return q
+}
+
+// FieldWithBoost adds a field to the query with the provided boost.
+func (q *SimpleQueryStringQuery) FieldWithBoost(field string, boost float32) *SimpleQueryStringQuery {
+	return q.Fields(boostedField(field, boost))
+}
+
+// DefaultOperator sets the boolean logic used to interpret text in the query
+// string when no operator is specified.
+func (q *SimpleQueryStringQuery) DefaultOperator(op MatchOperator) *SimpleQueryStringQuery {
+	q.params.DefaultOp = op
+	return q
+}
+
+// Analyzer sets the analyzer used to convert the text in the query string into
+// tokens.
+func (q *SimpleQueryStringQuery) Analyzer(a string) *SimpleQueryStringQuery {
+	q.params.Anl = a
+	return q
+}
+
+// Flags sets the operators enabled in the query string.
+func (q *SimpleQueryStringQuery) Flags(flags ...SimpleQueryStringFlag) *SimpleQueryStringQuery {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = string(f)
+	}
+	q.params.Flgs = strings.Join(names, "|")
+	return q
+}
+
+// QuoteFieldSuffix sets a suffix appended to the fields searched by quoted
+// text in the query string.
+func (q *SimpleQueryStringQuery) QuoteFieldSuffix(s string) *SimpleQueryStringQuery {
+	q.params.QuoteSuffix = s
+	return q
+}
+
+// AnalyzeWildcard sets whether prefix queries are analyzed.
+func (q *SimpleQueryStringQuery) AnalyzeWildcard(b bool) *SimpleQueryStringQuery {
+	q.params.AnalyzeWild = &b
+	return q
+}
+
+// AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
+// boolean.
+func (q *SimpleQueryStringQuery) AutoGenerateSynonymsPhraseQuery(b bool) *SimpleQueryStringQuery {
+	q.params.AutoGenerate = &b
+	return q
+}
+
+// FuzzyMaxExpansions sets the maximum number of terms to which fuzzy terms
+// will expand.
+func (q *SimpleQueryStringQuery) FuzzyMaxExpansions(e uint16) *SimpleQueryStringQuery {
+	q.params.FuzzyMaxExp = e
+	return q
+}
+
+// FuzzyPrefixLength sets the number of beginning characters left unchanged for
+// fuzzy matching.
+func (q *SimpleQueryStringQuery) FuzzyPrefixLength(l uint16) *SimpleQueryStringQuery {
+	q.params.FuzzyPrefLen = l
+	return q
+}
+
+// FuzzyTranspositions sets whether edits for fuzzy matching include transpositions
+// of two adjacent characters.
+func (q *SimpleQueryStringQuery) FuzzyTranspositions(b bool) *SimpleQueryStringQuery {
+	q.params.FuzzyTranspositions = &b
+	return q
+}
+
+// Lenient sets whether format-based errors should be ignored.
+func (q *SimpleQueryStringQuery) Lenient(b bool) *SimpleQueryStringQuery {
+	q.params.Lent = &b
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses that must match for a
+// document to be returned.
+func (q *SimpleQueryStringQuery) MinimumShouldMatch(s string) *SimpleQueryStringQuery {
+	q.params.MinMatch = s
+	return q
+}
+
+// Boost sets the boost value for the query.
+func (q *SimpleQueryStringQuery) Boost(l float32) *SimpleQueryStringQuery {
+	q.params.Boost = l
+	return q
+}
+
+// SimpleQueryStringFlag is an enumeration type representing supported values
+// for a simple query string query's "flags" parameter.
+type SimpleQueryStringFlag string
+
+const (
+	// FlagAll enables all operators (the default).
+	FlagAll SimpleQueryStringFlag = "ALL"
+
+	// FlagNone disables all operators.
+	FlagNone SimpleQueryStringFlag = "NONE"
+
+	// FlagAnd enables the "+" operator.
+	FlagAnd SimpleQueryStringFlag = "AND"
+
+	// FlagEscape enables "\" as an escape character.
+	FlagEscape SimpleQueryStringFlag = "ESCAPE"
+
+	// FlagFuzzy enables the "~N" operator after a word.
+	FlagFuzzy SimpleQueryStringFlag = "FUZZY"
+
+	// FlagNear enables the "~N" operator after a phrase.
+	FlagNear SimpleQueryStringFlag = "NEAR"
+
+	// FlagNot enables the "-" operator.
+	FlagNot SimpleQueryStringFlag = "NOT"
+
+	// FlagOr enables the "|" operator.
+	FlagOr SimpleQueryStringFlag = "OR"
+
+	// FlagPhrase enables quoting phrases.
+	FlagPhrase SimpleQueryStringFlag = "PHRASE"
+
+	// FlagPrecedence enables the "(" and ")" operators.
+	FlagPrecedence SimpleQueryStringFlag = "PRECEDENCE"
+
+	// FlagPrefix enables the "*" operator.
+	FlagPrefix SimpleQueryStringFlag = "PREFIX"
+
+	// FlagSlop enables the "~N" operator after a phrase, same as FlagNear.
+	FlagSlop SimpleQueryStringFlag = "SLOP"
+
+	// FlagWhitespace enables splitting the query string on whitespace.
+	FlagWhitespace SimpleQueryStringFlag = "WHITESPACE"
+)
+
+// boostedField returns the "field^boost" notation of a field and boost.
+func boostedField(field string, boost float32) string {
+	return field + "^" + strconv.FormatFloat(float64(boost), 'f', -1, 32)
+}
@@ -0,0 +1,132 @@
+package osquery
+
+import (
+	"testing"
+)
+
+func TestQueryString(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"simple query_string",
+			QueryString("(new york city) OR (big apple)").DefaultField("content"),
+			map[string]interface{}{
+				"query_string": map[string]interface{}{
+					"query":         "(new york city) OR (big apple)",
+					"default_field": "content",
+				},
+			},
+		},
+		{
+			"query_string all params",
+			QueryString("original").
+				Query("status:active AND title:(quick brown)").
+				Fields("title", "body").
+				FieldWithBoost("summary", 2.5).
+				Type(MatchTypeMostFields).
+				DefaultOperator(OperatorAnd).
+				Analyzer("standard").
+				QuoteAnalyzer("whitespace").
+				QuoteFieldSuffix(".exact").
+				PhraseSlop(2).
+				AllowLeadingWildcard(false).
+				AnalyzeWildcard(true).
+				EnablePositionIncrements(true).
+				AutoGenerateSynonymsPhraseQuery(false).
+				Fuzziness("AUTO").
+				FuzzyMaxExpansions(40).
+				FuzzyPrefixLength(1).
+				FuzzyTranspositions(true).
+				FuzzyRewrite("constant_score").
+				Lenient(true).
+				MaxDeterminizedStates(5000).
+				MinimumShouldMatch("2").
+				Rewrite("constant_score").
+				TimeZone("+01:00").
+				TieBreaker(0.3).
+				Boost(1.5).
+				Name("search_box"),
+			map[string]interface{}{
+				"query_string": map[string]interface{}{
+					"query":                               "status:active AND title:(quick brown)",
+					"fields":                              []string{"title", "body", "summary^2.5"},
+					"type":                                "most_fields",
+					"default_operator":                    "AND",
+					"analyzer":                            "standard",
+					"quote_analyzer":                      "whitespace",
+					"quote_field_suffix":                  ".exact",
+					"phrase_slop":                         2,
+					"allow_leading_wildcard":              false,
+					"analyze_wildcard":                    true,
+					"enable_position_increments":          true,
+					"auto_generate_synonyms_phrase_query": false,
+					"fuzziness":                           "AUTO",
+					"fuzzy_max_expansions":                40,
+					"fuzzy_prefix_length":                 1,
+					"fuzzy_transpositions":                true,
+					"fuzzy_rewrite":                       "constant_score",
+					"lenient":                             true,
+					"max_determinized_states":             5000,
+					"minimum_should_match":                "2",
+					"rewrite":                             "constant_score",
+					"time_zone":                           "+01:00",
+					"tie_breaker":                         0.3,
+					"boost":                               1.5,
+					"_name":                               "search_box",
+				},
+			},
+		},
+	})
+}
+
+func TestSimpleQueryString(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"simple simple_query_string",
+			SimpleQueryString(`"fried eggs" +(eggplant | potato) -frittata`),
+			map[string]interface{}{
+				"simple_query_string": map[string]interface{}{
+					"query": `"fried eggs" +(eggplant | potato) -frittata`,
+				},
+			},
+		},
+		{
+			"simple_query_string all params",
+			SimpleQueryString("original").
+				Query("quick brown~2").
+				Fields("title").
+				FieldWithBoost("body", 3).
+				DefaultOperator(OperatorAnd).
+				Analyzer("snowball").
+				Flags(FlagOr, FlagAnd, FlagPrefix).
+				QuoteFieldSuffix(".exact").
+				AnalyzeWildcard(true).
+				AutoGenerateSynonymsPhraseQuery(true).
+				FuzzyMaxExpansions(20).
+				FuzzyPrefixLength(2).
+				FuzzyTranspositions(false).
+				Lenient(true).
+				MinimumShouldMatch("75%").
+				Boost(2).
+				Name("simple"),
+			map[string]interface{}{
+				"simple_query_string": map[string]interface{}{
+					"query":                               "quick brown~2",
+					"fields":                              []string{"title", "body^3"},
+					"default_operator":                    "AND",
+					"analyzer":                            "snowball",
+					"flags":                               "OR|AND|PREFIX",
+					"quote_field_suffix":                  ".exact",
+					"analyze_wildcard":                    true,
+					"auto_generate_synonyms_phrase_query": true,
+					"fuzzy_max_expansions":                20,
+					"fuzzy_prefix_length":                 2,
+					"fuzzy_transpositions":                false,
+					"lenient":                             true,
+					"minimum_should_match":                "75%",
+					"boost":                               2,
+					"_name":                               "simple",
+				},
+			},
+		},
+	})
+}
@@ -18,10 +18,13 @@ type ScriptScoreQuery struct {
 // ScriptScore creates a new query of type "script_score" with the provided
 // query and script.
 func ScriptScore(query Mappable, script *ScriptField) *ScriptScoreQuery {
-	return &ScriptScoreQuery{
-		query:  query,
-		script: *script,
+	q := &ScriptScoreQuery{
+		query: query,
 	}
+	if script != nil {
+		q.script = *script
+	}
+	return q
 }
 
 // Boost sets the boost value of the query.
@@ -39,7 +42,7 @@ func (q *ScriptScoreQuery) MinScore(min float32) *ScriptScoreQuery {
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *ScriptScoreQuery) Map() map[string]interface{} {
-	script := q.script.Map()["script"].(map[string]interface{})
+	script := scriptMap(&q.script)
 	return map[string]interface{}{
 		"script_score": structs.Map(struct {
 			Query    map[string]interface{} `structs:"query"`
@@ -49,3 +52,22 @@ func (q *ScriptScoreQuery) Map() map[string]interface{} {
 		}{q.query.Map(), script, q.boost, q.minScore}),
 	}
 }
+
+// Validate checks the query and its sub-queries for problems that would make
+// OpenSearch reject it. It returns a ValidationErrors value listing all the
+// problems found, or nil.
+func (q *ScriptScoreQuery) Validate() error {
+	return validate(q, "")
+}
+
+func (q *ScriptScoreQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "script_score")
+	if q.script.Src == "" && q.script.Id == "" {
+		errs.add(path, "script requires a source or an id")
+	}
+	if q.query == nil {
+		errs.add(path, "query is required")
+		return
+	}
+	validateNode(q.query, path+".query", errs)
+}
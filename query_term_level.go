@@ -5,6 +5,8 @@
 package osquery
 
 import (
+	"io"
+
 	"github.com/fatih/structs"
 )
 
@@ -28,6 +30,22 @@ func (q *ExistsQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *ExistsQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *ExistsQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("exists")
+	e.openObject()
+	e.key("field")
+	e.str(q.Field)
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // IDsQuery represents a query of type "ids", as described in:
@@ -53,6 +71,22 @@ func (q *IDsQuery) Map() map[string]interface{} {
 	return structs.Map(q)
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *IDsQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *IDsQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("ids")
+	e.openObject()
+	e.key("values")
+	e.strings(q.IDs.Values)
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // PrefixQuery represents query of type "prefix", as described in:
@@ -95,6 +129,29 @@ func (q *PrefixQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *PrefixQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *PrefixQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("prefix")
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.params.Rewrite != "" {
+		e.key("rewrite")
+		e.str(q.params.Rewrite)
+	}
+	e.key("value")
+	e.str(q.params.Value)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // RangeQuery represents a query of type "range", as described in:
@@ -180,6 +237,67 @@ func (a *RangeQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (a *RangeQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, a)
+}
+
+func (a *RangeQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("range")
+	e.openObject()
+	e.key(a.field)
+	e.openObject()
+	if a.params.Boost != 0 {
+		e.key("boost")
+		e.float(float64(a.params.Boost), 32)
+	}
+	if a.params.Format != "" {
+		e.key("format")
+		e.str(a.params.Format)
+	}
+	for _, bound := range []struct {
+		key   string
+		value interface{}
+	}{
+		{"gt", a.params.Gt},
+		{"gte", a.params.Gte},
+		{"lt", a.params.Lt},
+		{"lte", a.params.Lte},
+	} {
+		if bound.value != nil {
+			e.key(bound.key)
+			e.value(bound.value)
+		}
+	}
+	if a.params.Relation != 0 {
+		e.key("relation")
+		e.str(a.params.Relation.String())
+	}
+	if a.params.TimeZone != "" {
+		e.key("time_zone")
+		e.str(a.params.TimeZone)
+	}
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks the query for problems that would make OpenSearch reject
+// it. It returns a ValidationErrors value listing all the problems found, or
+// nil.
+func (a *RangeQuery) Validate() error {
+	return validate(a, "")
+}
+
+func (a *RangeQuery) validate(path string, errs *ValidationErrors) {
+	path = joinPath(path, "range."+a.field)
+	if a.params.Gt == nil && a.params.Gte == nil && a.params.Lt == nil && a.params.Lte == nil {
+		errs.add(path, "range query has no bounds")
+	}
+}
+
 // RangeRelation is an enumeration type for a range query's "relation" field
 type RangeRelation uint8
 
@@ -284,6 +402,41 @@ func (q *RegexpQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *RegexpQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *RegexpQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	if q.wildcard {
+		e.key("wildcard")
+	} else {
+		e.key("regexp")
+	}
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.params.Flags != "" {
+		e.key("flags")
+		e.str(q.params.Flags)
+	}
+	if q.params.MaxDeterminizedStates != 0 {
+		e.key("max_determinized_states")
+		e.uint(uint64(q.params.MaxDeterminizedStates))
+	}
+	if q.params.Rewrite != "" {
+		e.key("rewrite")
+		e.str(q.params.Rewrite)
+	}
+	e.key("value")
+	e.str(q.params.Value)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // Wildcard creates a new query of type "wildcard" on the provided field and
@@ -378,6 +531,45 @@ func (q *FuzzyQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *FuzzyQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *FuzzyQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("fuzzy")
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.params.Fuzziness != "" {
+		e.key("fuzziness")
+		e.str(q.params.Fuzziness)
+	}
+	if q.params.MaxExpansions != 0 {
+		e.key("max_expansions")
+		e.uint(uint64(q.params.MaxExpansions))
+	}
+	if q.params.PrefixLength != 0 {
+		e.key("prefix_length")
+		e.uint(uint64(q.params.PrefixLength))
+	}
+	if q.params.Rewrite != "" {
+		e.key("rewrite")
+		e.str(q.params.Rewrite)
+	}
+	if q.params.Transpositions != nil {
+		e.key("transpositions")
+		e.boolean(*q.params.Transpositions)
+	}
+	e.key("value")
+	e.str(q.params.Value)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermQuery represents a query of type "term", as described in:
@@ -440,6 +632,37 @@ func (q *TermQuery) Map() map[string]interface{} {
 	}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q *TermQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q *TermQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("term")
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.params.Name != "" {
+		e.key("_name")
+		e.str(q.params.Name)
+	}
+	if q.params.Boost != 0 {
+		e.key("boost")
+		e.float(float64(q.params.Boost), 32)
+	}
+	if q.params.CaseInsensitive {
+		e.key("case_insensitive")
+		e.boolean(true)
+	}
+	e.key("value")
+	e.value(q.params.Value)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermsQuery represents a query of type "terms", as described in:
@@ -482,6 +705,38 @@ func (q TermsQuery) Map() map[string]interface{} {
 	return map[string]interface{}{"terms": innerMap}
 }
 
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q TermsQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q TermsQuery) encodeJSON(e *encoder) {
+	values := func() {
+		e.key(q.field)
+		e.value(q.values)
+	}
+
+	e.openObject()
+	e.key("terms")
+	e.openObject()
+	if q.boost > 0 {
+		// members are written in key order, as encoding/json does
+		if q.field < "boost" {
+			values()
+		}
+		e.key("boost")
+		e.float(float64(q.boost), 32)
+		if q.field > "boost" {
+			values()
+		}
+	} else {
+		values()
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermsSetQuery represents a query of type "terms_set", as described in:
@@ -537,3 +792,30 @@ func (q TermsSetQuery) Map() map[string]interface{} {
 		},
 	}
 }
+
+// WriteJSON writes the JSON encoding of the query to w, thus implementing the
+// JSONWriter interface.
+func (q TermsSetQuery) WriteJSON(w io.Writer) error {
+	return writeJSON(w, q)
+}
+
+func (q TermsSetQuery) encodeJSON(e *encoder) {
+	e.openObject()
+	e.key("terms_set")
+	e.openObject()
+	e.key(q.field)
+	e.openObject()
+	if q.params.MinimumShouldMatchField != "" {
+		e.key("minimum_should_match_field")
+		e.str(q.params.MinimumShouldMatchField)
+	}
+	if q.params.MinimumShouldMatchScript != "" {
+		e.key("minimum_should_match_script")
+		e.str(q.params.MinimumShouldMatchScript)
+	}
+	e.key("terms")
+	e.strings(q.params.Terms)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
@@ -0,0 +1,348 @@
+package osquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// ReindexRequest represents a request to OpenSearch's Reindex API, described
+// in https://opensearch.org/docs/latest/api-reference/document-apis/reindex/
+type ReindexRequest struct {
+	sourceIndex       []string
+	sourceQuery       Mappable
+	sourceFields      []string
+	sourceSize        *uint64
+	remote            *ReindexRemote
+	destIndex         string
+	opType            OpType
+	destPipeline      string
+	script            *ScriptField
+	conflicts         Conflicts
+	maxDocs           *int
+	slices            interface{}
+	requestsPerSecond *int
+	refresh           *bool
+}
+
+// Reindex creates a new ReindexRequest object, to be filled via method
+// chaining. At least a source index and a destination index must be set.
+func Reindex() *ReindexRequest {
+	return &ReindexRequest{}
+}
+
+// SourceIndex sets the indices to copy documents from.
+func (req *ReindexRequest) SourceIndex(index ...string) *ReindexRequest {
+	req.sourceIndex = index
+	return req
+}
+
+// SourceQuery sets a query selecting the documents to copy.
+func (req *ReindexRequest) SourceQuery(q Mappable) *ReindexRequest {
+	req.sourceQuery = q
+	return req
+}
+
+// SourceFields sets the fields of the source documents to copy.
+func (req *ReindexRequest) SourceFields(fields ...string) *ReindexRequest {
+	req.sourceFields = fields
+	return req
+}
+
+// SourceSize sets the number of documents copied per batch.
+func (req *ReindexRequest) SourceSize(size uint64) *ReindexRequest {
+	req.sourceSize = &size
+	return req
+}
+
+// Remote sets the remote cluster to copy documents from.
+func (req *ReindexRequest) Remote(remote *ReindexRemote) *ReindexRequest {
+	req.remote = remote
+	return req
+}
+
+// DestIndex sets the index to copy documents to.
+func (req *ReindexRequest) DestIndex(index string) *ReindexRequest {
+	req.destIndex = index
+	return req
+}
+
+// OpType sets whether documents are indexed in the destination index
+// regardless of whether they already exist, or only created if missing.
+func (req *ReindexRequest) OpType(opType OpType) *ReindexRequest {
+	req.opType = opType
+	return req
+}
+
+// DestPipeline sets the ingest pipeline documents are run through when
+// written to the destination index.
+func (req *ReindexRequest) DestPipeline(pipeline string) *ReindexRequest {
+	req.destPipeline = pipeline
+	return req
+}
+
+// Script sets the script used to transform each document.
+func (req *ReindexRequest) Script(script *ScriptField) *ReindexRequest {
+	req.script = script
+	return req
+}
+
+// Conflicts sets what to do on version conflicts. The default is to abort the
+// request.
+func (req *ReindexRequest) Conflicts(c Conflicts) *ReindexRequest {
+	req.conflicts = c
+	return req
+}
+
+// MaxDocs sets the maximum number of documents to copy.
+func (req *ReindexRequest) MaxDocs(n int) *ReindexRequest {
+	req.maxDocs = &n
+	return req
+}
+
+// Slices sets the number of slices the request is divided into.
+func (req *ReindexRequest) Slices(n int) *ReindexRequest {
+	req.slices = n
+	return req
+}
+
+// AutoSlices lets OpenSearch choose the number of slices the request is
+// divided into.
+func (req *ReindexRequest) AutoSlices() *ReindexRequest {
+	req.slices = "auto"
+	return req
+}
+
+// RequestsPerSecond throttles the request to the provided number of requests
+// per second. A value of -1 disables throttling.
+func (req *ReindexRequest) RequestsPerSecond(n int) *ReindexRequest {
+	req.requestsPerSecond = &n
+	return req
+}
+
+// Refresh sets whether the destination index is refreshed once the request
+// completes.
+func (req *ReindexRequest) Refresh(b bool) *ReindexRequest {
+	req.refresh = &b
+	return req
+}
+
+// Map returns a map representation of the request body, thus implementing the
+// Mappable interface.
+func (req *ReindexRequest) Map() map[string]interface{} {
+	source := map[string]interface{}{
+		"index": req.sourceIndex,
+	}
+	if req.sourceQuery != nil {
+		source["query"] = req.sourceQuery.Map()
+	}
+	if len(req.sourceFields) > 0 {
+		source["_source"] = req.sourceFields
+	}
+	if req.sourceSize != nil {
+		source["size"] = *req.sourceSize
+	}
+	if req.remote != nil {
+		source["remote"] = req.remote.Map()
+	}
+
+	dest := map[string]interface{}{
+		"index": req.destIndex,
+	}
+	if req.opType != "" {
+		dest["op_type"] = req.opType
+	}
+	if req.destPipeline != "" {
+		dest["pipeline"] = req.destPipeline
+	}
+
+	m := map[string]interface{}{
+		"source": source,
+		"dest":   dest,
+	}
+	if req.script != nil {
+		m["script"] = scriptMap(req.script)
+	}
+	if req.conflicts != "" {
+		m["conflicts"] = req.conflicts
+	}
+	if req.maxDocs != nil {
+		m["max_docs"] = *req.maxDocs
+	}
+
+	return m
+}
+
+// Run executes the request using the provided OpenSearch client, waiting for
+// it to complete. Values set on the request itself take precedence
+// over the options' Params. The options' Indices are ignored.
+func (req *ReindexRequest) Run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *ReindexOptions,
+) (*BulkByScrollResponse, error) {
+	var res BulkByScrollResponse
+	if err := req.run(ctx, client, options, true, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// RunAsync starts the request using the provided OpenSearch client without
+// waiting for it to complete, and returns a handle on the task running it.
+func (req *ReindexRequest) RunAsync(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *ReindexOptions,
+) (*Task, error) {
+	var res struct {
+		Task string `json:"task"`
+	}
+	if err := req.run(ctx, client, options, false, &res); err != nil {
+		return nil, err
+	}
+
+	return &Task{ID: res.Task, api: "reindex"}, nil
+}
+
+func (req *ReindexRequest) run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *ReindexOptions,
+	wait bool,
+	out interface{},
+) error {
+	if len(req.sourceIndex) == 0 || req.destIndex == "" {
+		return errors.New("reindex requires a source index and a destination index")
+	}
+	if err := validateQuery(req.sourceQuery, "source.query"); err != nil {
+		return fmt.Errorf("invalid reindex request: %w", err)
+	}
+
+	// Serialize the request body to JSON
+	body, err := json.Marshal(req.Map())
+	if err != nil {
+		return fmt.Errorf("failed to serialize request body: %w", err)
+	}
+
+	reindexReq := opensearchapi.ReindexReq{
+		Body: bytes.NewReader(body),
+	}
+
+	// Apply additional options if provided
+	options.Apply(nil, &reindexReq.Header, &reindexReq.Params)
+
+	params := &reindexReq.Params
+	if req.slices != nil {
+		params.Slices = req.slices
+	}
+	if req.requestsPerSecond != nil {
+		params.RequestsPerSecond = req.requestsPerSecond
+	}
+	if req.refresh != nil {
+		params.Refresh = req.refresh
+	}
+	if !wait {
+		params.WaitForCompletion = &wait
+	}
+
+	if err := execute(ctx, client, reindexReq, out); err != nil {
+		return fmt.Errorf("reindex request failed: %w", err)
+	}
+
+	return nil
+}
+
+// OpType is an enumeration type representing supported values for a reindex
+// request's destination "op_type" parameter.
+type OpType string
+
+const (
+	// OpTypeIndex indexes documents, overwriting existing ones (the default).
+	OpTypeIndex OpType = "index"
+
+	// OpTypeCreate only creates documents missing from the destination index.
+	OpTypeCreate OpType = "create"
+)
+
+//----------------------------------------------------------------------------//
+
+// ReindexRemote represents a remote cluster to reindex documents from.
+type ReindexRemote struct {
+	host           string
+	username       string
+	password       string
+	socketTimeout  string
+	connectTimeout string
+	headers        map[string]string
+}
+
+// RemoteSource creates a new remote cluster for a reindex request, with the
+// provided host, e.g. "https://other-cluster:9200".
+func RemoteSource(host string) *ReindexRemote {
+	return &ReindexRemote{
+		host: host,
+	}
+}
+
+// Username sets the username used to authenticate with the remote cluster.
+func (r *ReindexRemote) Username(username string) *ReindexRemote {
+	r.username = username
+	return r
+}
+
+// Password sets the password used to authenticate with the remote cluster.
+func (r *ReindexRemote) Password(password string) *ReindexRemote {
+	r.password = password
+	return r
+}
+
+// SocketTimeout sets the socket read timeout for the remote cluster, e.g.
+// "1m".
+func (r *ReindexRemote) SocketTimeout(timeout string) *ReindexRemote {
+	r.socketTimeout = timeout
+	return r
+}
+
+// ConnectTimeout sets the connection timeout for the remote cluster, e.g.
+// "10s".
+func (r *ReindexRemote) ConnectTimeout(timeout string) *ReindexRemote {
+	r.connectTimeout = timeout
+	return r
+}
+
+// Headers sets headers sent with every request to the remote cluster.
+func (r *ReindexRemote) Headers(headers map[string]string) *ReindexRemote {
+	r.headers = headers
+	return r
+}
+
+// Map returns a map representation of the remote cluster, thus implementing
+// the Mappable interface.
+func (r *ReindexRemote) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"host": r.host,
+	}
+	if r.username != "" {
+		m["username"] = r.username
+	}
+	if r.password != "" {
+		m["password"] = r.password
+	}
+	if r.socketTimeout != "" {
+		m["socket_timeout"] = r.socketTimeout
+	}
+	if r.connectTimeout != "" {
+		m["connect_timeout"] = r.connectTimeout
+	}
+	if len(r.headers) > 0 {
+		m["headers"] = r.headers
+	}
+	return m
+}
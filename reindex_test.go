@@ -0,0 +1,129 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestReindex(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"a simple reindex request",
+			Reindex().SourceIndex("posts").DestIndex("posts-v2"),
+			map[string]interface{}{
+				"source": map[string]interface{}{
+					"index": []string{"posts"},
+				},
+				"dest": map[string]interface{}{
+					"index": "posts-v2",
+				},
+			},
+		},
+		{
+			"reindex with all body params",
+			Reindex().
+				SourceIndex("posts", "archive").
+				SourceQuery(Term("tag", "tech")).
+				SourceFields("title", "tag").
+				SourceSize(500).
+				Remote(RemoteSource("https://other-cluster:9200").
+					Username("admin").
+					Password("secret").
+					SocketTimeout("1m").
+					ConnectTimeout("10s").
+					Headers(map[string]string{"X-Source": "osquery"})).
+				DestIndex("posts-v2").
+				OpType(OpTypeCreate).
+				DestPipeline("set-timestamp").
+				Script(Script("").Source("ctx._source.tag = ctx._source.tag.toLowerCase()")).
+				Conflicts(ConflictsProceed).
+				MaxDocs(10000),
+			map[string]interface{}{
+				"source": map[string]interface{}{
+					"index": []string{"posts", "archive"},
+					"query": map[string]interface{}{
+						"term": map[string]interface{}{
+							"tag": map[string]interface{}{
+								"value": "tech",
+							},
+						},
+					},
+					"_source": []string{"title", "tag"},
+					"size":    500,
+					"remote": map[string]interface{}{
+						"host":            "https://other-cluster:9200",
+						"username":        "admin",
+						"password":        "secret",
+						"socket_timeout":  "1m",
+						"connect_timeout": "10s",
+						"headers":         map[string]string{"X-Source": "osquery"},
+					},
+				},
+				"dest": map[string]interface{}{
+					"index":    "posts-v2",
+					"op_type":  "create",
+					"pipeline": "set-timestamp",
+				},
+				"script": map[string]interface{}{
+					"source": "ctx._source.tag = ctx._source.tag.toLowerCase()",
+				},
+				"conflicts": "proceed",
+				"max_docs":  10000,
+			},
+		},
+	})
+}
+
+func TestReindexRun(t *testing.T) {
+	client, requests := newTestClient(t, `{
+		"took": 20,
+		"timed_out": false,
+		"total": 10,
+		"created": 8,
+		"updated": 2,
+		"batches": 1,
+		"failures": []
+	}`)
+
+	res, err := Reindex().
+		SourceIndex("posts").
+		DestIndex("posts-v2").
+		AutoSlices().
+		RequestsPerSecond(1000).
+		Refresh(true).
+		Run(context.Background(), client, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), res.Created)
+	assert.Equal(t, int64(2), res.Updated)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/_reindex", (*requests)[0].path)
+	assert.Equal(t, "refresh=true&requests_per_second=1000&slices=auto", (*requests)[0].query)
+	assert.Equal(t, `{"dest":{"index":"posts-v2"},"source":{"index":["posts"]}}`, (*requests)[0].body)
+}
+
+func TestReindexRunAsync(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"task": "abc:7"}`,
+		`{"nodes": {}}`,
+	)
+
+	ctx := context.Background()
+	task, err := Reindex().SourceIndex("posts").DestIndex("posts-v2").RunAsync(ctx, client, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc:7", task.ID)
+	assert.Equal(t, "wait_for_completion=false", (*requests)[0].query)
+
+	assert.Nil(t, task.Rethrottle(ctx, client, -1))
+	assert.Equal(t, "/_reindex/abc:7/_rethrottle", (*requests)[1].path)
+}
+
+func TestReindexRunWithoutIndices(t *testing.T) {
+	client, requests := newTestClient(t)
+
+	_, err := Reindex().SourceIndex("posts").Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(*requests))
+}
@@ -0,0 +1,111 @@
+package osquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	opensearchapi "github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// SearchResult is a typed representation of a search response. The "_source"
+// of every hit is decoded into a value of type T, while the metadata of each
+// hit (ID, score, sort values, highlights, inner hits) is kept alongside it.
+type SearchResult[T any] struct {
+	Took         int                          `json:"took"`
+	TimedOut     bool                         `json:"timed_out"`
+	Shards       opensearchapi.ResponseShards `json:"_shards"`
+	Hits         Hits[T]                      `json:"hits"`
+	Aggregations AggregationResults           `json:"aggregations,omitempty"`
+	ScrollID     string                       `json:"_scroll_id,omitempty"`
+	PitID        string                       `json:"pit_id,omitempty"`
+}
+
+// Documents returns the decoded documents of all hits, in order.
+func (res *SearchResult[T]) Documents() []T {
+	docs := make([]T, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		docs[i] = hit.Source
+	}
+	return docs
+}
+
+// Hits represents the "hits" section of a search response.
+type Hits[T any] struct {
+	Total    HitsTotal `json:"total"`
+	MaxScore *float64  `json:"max_score"`
+	Hits     []Hit[T]  `json:"hits"`
+}
+
+// HitsTotal is the total number of documents matching a search, as reported
+// by OpenSearch. Relation is "eq" when the value is accurate, and "gte" when it
+// is a lower bound.
+type HitsTotal struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// Hit is a single search hit, with its "_source" decoded into a value of type
+// T.
+type Hit[T any] struct {
+	Index          string                     `json:"_index"`
+	ID             string                     `json:"_id"`
+	Routing        string                     `json:"_routing,omitempty"`
+	Score          *float64                   `json:"_score"`
+	Source         T                          `json:"_source"`
+	Fields         map[string]json.RawMessage `json:"fields,omitempty"`
+	Sort           []interface{}              `json:"sort,omitempty"`
+	Highlight      map[string][]string        `json:"highlight,omitempty"`
+	InnerHits      map[string]InnerHits       `json:"inner_hits,omitempty"`
+	MatchedQueries []string                   `json:"matched_queries,omitempty"`
+	SeqNo          *int64                     `json:"_seq_no,omitempty"`
+	PrimaryTerm    *int64                     `json:"_primary_term,omitempty"`
+}
+
+// InnerHits holds the undecoded inner hits returned for a hit. Inner hits may
+// refer to documents of a different shape than the parent hit, so they are
+// decoded separately using DecodeInnerHits.
+type InnerHits struct {
+	Hits json.RawMessage `json:"hits"`
+}
+
+// DecodeInnerHits decodes inner hits into hits carrying documents of type U.
+func DecodeInnerHits[U any](inner InnerHits) (*Hits[U], error) {
+	var hits Hits[U]
+	if len(inner.Hits) == 0 {
+		return &hits, nil
+	}
+	if err := json.Unmarshal(inner.Hits, &hits); err != nil {
+		return nil, fmt.Errorf("failed to decode inner hits: %w", err)
+	}
+	return &hits, nil
+}
+
+// RunInto executes the search request like SearchRequest.Run, but decodes the
+// response into a SearchResult whose hits carry documents of type T. Custom
+// queries are executed the same way with RunCustomInto.
+func RunInto[T any](
+	ctx context.Context,
+	req *SearchRequest,
+	client *opensearch.Client,
+	options *SearchOptions,
+) (*SearchResult[T], error) {
+	var res SearchResult[T]
+	if err := req.run(ctx, client, options, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// RunCustomInto executes a custom query like CustomQueryMap.Run, but decodes
+// the response into a SearchResult whose hits carry documents of type T.
+func RunCustomInto[T any](
+	ctx context.Context,
+	q *CustomQueryMap,
+	client *opensearch.Client,
+	options *SearchOptions,
+) (*SearchResult[T], error) {
+	return RunInto[T](ctx, Search().Query(q), client, options)
+}
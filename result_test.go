@@ -0,0 +1,120 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+type testDoc struct {
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+type testComment struct {
+	Author string `json:"author"`
+}
+
+const testSearchResponse = `{
+	"took": 3,
+	"timed_out": false,
+	"_shards": {"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+	"hits": {
+		"total": {"value": 2, "relation": "eq"},
+		"max_score": 1.5,
+		"hits": [
+			{
+				"_index": "posts",
+				"_id": "1",
+				"_score": 1.5,
+				"_source": {"title": "Go and Stuff", "views": 10},
+				"sort": [10, "1"],
+				"highlight": {"title": ["<em>Go</em> and Stuff"]},
+				"matched_queries": ["by_title"],
+				"inner_hits": {
+					"comments": {
+						"hits": {
+							"total": {"value": 1, "relation": "eq"},
+							"max_score": 1.0,
+							"hits": [
+								{"_index": "posts", "_id": "1", "_score": 1.0, "_source": {"author": "kimchy"}}
+							]
+						}
+					}
+				}
+			},
+			{
+				"_index": "posts",
+				"_id": "2",
+				"_score": null,
+				"_source": {"title": "More Stuff", "views": 3}
+			}
+		]
+	},
+	"aggregations": {"max_views": {"value": 10}}
+}`
+
+func TestRunInto(t *testing.T) {
+	client, requests := newTestClient(t, testSearchResponse)
+
+	res, err := RunInto[testDoc](
+		context.Background(),
+		Query(Term("title", "Go")).Size(2),
+		client,
+		&SearchOptions{Indices: []string{"posts"}},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/posts/_search", (*requests)[0].path)
+	assert.Equal(t, `{"query":{"term":{"title":{"value":"Go"}}},"size":2}`, (*requests)[0].body)
+
+	assert.Equal(t, 3, res.Took)
+	assert.Equal(t, int64(2), res.Hits.Total.Value)
+	assert.Equal(t, "eq", res.Hits.Total.Relation)
+	assert.DeepEqual(t, []testDoc{
+		{Title: "Go and Stuff", Views: 10},
+		{Title: "More Stuff", Views: 3},
+	}, res.Documents())
+
+	first := res.Hits.Hits[0]
+	assert.Equal(t, "1", first.ID)
+	assert.Equal(t, 1.5, *first.Score)
+	assert.DeepEqual(t, []interface{}{float64(10), "1"}, first.Sort)
+	assert.DeepEqual(t, []string{"<em>Go</em> and Stuff"}, first.Highlight["title"])
+	assert.DeepEqual(t, []string{"by_title"}, first.MatchedQueries)
+	assert.True(t, res.Hits.Hits[1].Score == nil)
+	maxViews, err := Max("max_views", "views").Result(res.Aggregations)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, *maxViews.Value)
+
+	comments, err := DecodeInnerHits[testComment](first.InnerHits["comments"])
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(comments.Hits))
+	assert.Equal(t, "kimchy", comments.Hits[0].Source.Author)
+}
+
+func TestRunIntoCustomQuery(t *testing.T) {
+	client, requests := newTestClient(t, testSearchResponse)
+
+	res, err := RunCustomInto[testDoc](
+		context.Background(),
+		CustomQuery(map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		}),
+		client,
+		&SearchOptions{Indices: []string{"posts"}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "/posts/_search", (*requests)[0].path)
+	assert.Equal(t, `{"query":{"match_all":{}}}`, (*requests)[0].body)
+	assert.Equal(t, 2, len(res.Documents()))
+}
+
+func TestRunIntoError(t *testing.T) {
+	client, _ := newTestClient(t, `{"error":{"type":"index_not_found_exception","reason":"no such index [posts]"},"status":404}`)
+
+	_, err := RunInto[testDoc](context.Background(), Search(), client, nil)
+	assert.NotNil(t, err)
+}
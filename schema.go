@@ -0,0 +1,623 @@
+package osquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema describes the fields of one or more indices, as defined by their
+// mappings. It checks the field references of queries, aggregations and
+// search requests, catching misspelled field names and fields whose type does
+// not suit the way they are used, which OpenSearch often silently ignores.
+type Schema struct {
+	fields map[string]*MappedField
+}
+
+// MappedField is a field of an index mapping. Sub-fields of objects and
+// multi-fields are separate fields named after their full path, such as
+// "user.name" or "title.keyword".
+type MappedField struct {
+	// Name is the full path of the field.
+	Name string
+
+	// Type is the mapping type of the field, such as "text", "keyword" or
+	// "long". Objects without an explicit type are of type "object".
+	Type string
+
+	// Fielddata is true for text fields with fielddata enabled, which can be
+	// used in aggregations and sorts.
+	Fielddata bool
+
+	// NestedPath is the path of the closest nested object containing the
+	// field, if any.
+	NestedPath string
+}
+
+// ParseMapping parses index mappings into a Schema. It accepts the response of
+// the get mapping API, which may hold the mappings of several indices, the
+// body of a create index request, or a mapping object with "properties".
+// Fields of several indices are merged, and an error is returned if a field is
+// mapped with different types. Alias fields are resolved to the fields they
+// point to.
+func ParseMapping(data []byte) (*Schema, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mapping: expected an object, got %s", jsonType(v))
+	}
+
+	l := schemaLoader{
+		schema:  &Schema{fields: make(map[string]*MappedField)},
+		aliases: make(map[string]string),
+	}
+	switch {
+	case m["properties"] != nil:
+		err = l.loadMapping(m, "mapping")
+	case m["mappings"] != nil:
+		err = l.loadMapping(m["mappings"], "mappings")
+	default:
+		for _, index := range sortedKeys(m) {
+			body, ok := m[index].(map[string]interface{})
+			if !ok || body["mappings"] == nil {
+				return nil, fmt.Errorf("%s: expected an object with mappings", index)
+			}
+			if err = l.loadMapping(body["mappings"], index+".mappings"); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := l.resolveAliases(); err != nil {
+		return nil, err
+	}
+	return l.schema, nil
+}
+
+// Field returns the field with the provided full path.
+func (s *Schema) Field(name string) (*MappedField, bool) {
+	f, ok := s.fields[name]
+	return f, ok
+}
+
+// Fields returns the full paths of all the fields of the schema, sorted.
+func (s *Schema) Fields() []string {
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks every field reference of a search request, query or
+// aggregation tree against the schema. It reports fields that are not mapped,
+// fields whose type does not suit the query or aggregation using them, such as
+// a range query on a text field or a terms aggregation on an analyzed field,
+// and fields of nested objects used outside of a nested query or aggregation.
+// Problems are returned as ValidationErrors, whose paths locate the offending
+// nodes as the Validate methods of the library's types do. Custom queries and
+// aggregations are not checked.
+func (s *Schema) Validate(node Mappable) error {
+	c := schemaChecker{schema: s}
+	c.node(node, "")
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+//----------------------------------------------------------------------------//
+
+// schemaLoader builds a Schema from the mappings of one or more indices.
+type schemaLoader struct {
+	schema  *Schema
+	aliases map[string]string
+}
+
+func (l *schemaLoader) loadMapping(v interface{}, path string) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected an object, got %s", path, jsonType(v))
+	}
+	if m["properties"] == nil {
+		// an index without fields
+		return nil
+	}
+	return l.loadProperties(m["properties"], "", "", path+".properties")
+}
+
+func (l *schemaLoader) loadProperties(v interface{}, prefix, nestedPath, path string) error {
+	props, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected an object, got %s", path, jsonType(v))
+	}
+
+	for _, name := range sortedKeys(props) {
+		propPath := joinPath(path, name)
+		def, ok := props[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", propPath, jsonType(props[name]))
+		}
+
+		f := &MappedField{
+			Name:       prefix + name,
+			Type:       "object",
+			NestedPath: nestedPath,
+		}
+		if typ, ok := def["type"]; ok {
+			if f.Type, ok = typ.(string); !ok {
+				return fmt.Errorf("%s.type: expected a string, got %s", propPath, jsonType(typ))
+			}
+		}
+		f.Fielddata, _ = def["fielddata"].(bool)
+
+		if f.Type == "alias" {
+			target, ok := def["path"].(string)
+			if !ok {
+				return fmt.Errorf("%s: alias requires a path", propPath)
+			}
+			l.aliases[f.Name] = target
+			continue
+		}
+		if err := l.add(f); err != nil {
+			return err
+		}
+
+		if sub, ok := def["properties"]; ok {
+			subNested := nestedPath
+			if f.Type == "nested" {
+				subNested = f.Name
+			}
+			if err := l.loadProperties(sub, f.Name+".", subNested, propPath+".properties"); err != nil {
+				return err
+			}
+		}
+		if multi, ok := def["fields"]; ok {
+			if err := l.loadProperties(multi, f.Name+".", nestedPath, propPath+".fields"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *schemaLoader) add(f *MappedField) error {
+	if prev, ok := l.schema.fields[f.Name]; ok {
+		if prev.Type != f.Type {
+			return fmt.Errorf("field %q is mapped as both %s and %s", f.Name, prev.Type, f.Type)
+		}
+		prev.Fielddata = prev.Fielddata && f.Fielddata
+		return nil
+	}
+	l.schema.fields[f.Name] = f
+	return nil
+}
+
+func (l *schemaLoader) resolveAliases() error {
+	names := make([]string, 0, len(l.aliases))
+	for name := range l.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		target, ok := l.schema.fields[l.aliases[name]]
+		if !ok {
+			return fmt.Errorf("alias %q points to unknown field %q", name, l.aliases[name])
+		}
+		alias := *target
+		alias.Name = name
+		if err := l.add(&alias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//----------------------------------------------------------------------------//
+
+// fieldUse describes how a query or aggregation uses a field, and which field
+// types suit it.
+type fieldUse struct {
+	desc    string
+	accepts func(f *MappedField) bool
+}
+
+var (
+	textTypes    = typeSet("text", "match_only_text", "search_as_you_type")
+	keywordTypes = typeSet("keyword", "constant_keyword", "wildcard")
+	numericTypes = typeSet("long", "integer", "short", "byte", "double", "float",
+		"half_float", "scaled_float", "unsigned_long", "token_count")
+	dateTypes  = typeSet("date", "date_nanos")
+	rangeTypes = typeSet("integer_range", "long_range", "float_range",
+		"double_range", "date_range", "ip_range")
+	objectTypes = typeSet("object", "nested", "flat_object")
+)
+
+func typeSet(types ...string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, typ := range types {
+		set[typ] = true
+	}
+	return set
+}
+
+func (f *MappedField) analyzed() bool {
+	return textTypes[f.Type]
+}
+
+func (f *MappedField) aggregatable() bool {
+	return !objectTypes[f.Type] && f.Type != "knn_vector" && f.Type != "binary" &&
+		(!f.analyzed() || f.Fielddata)
+}
+
+func isLeaf(f *MappedField) bool {
+	return !objectTypes[f.Type]
+}
+
+func isNumeric(f *MappedField) bool {
+	return numericTypes[f.Type]
+}
+
+func isDate(f *MappedField) bool {
+	return dateTypes[f.Type]
+}
+
+var (
+	useExists = fieldUse{"an exists query", func(*MappedField) bool { return true }}
+	useTerm   = fieldUse{"a term-level query", func(f *MappedField) bool {
+		return isLeaf(f) && f.Type != "knn_vector"
+	}}
+	usePattern = fieldUse{"a pattern query", func(f *MappedField) bool {
+		return f.analyzed() || keywordTypes[f.Type]
+	}}
+	useRange = fieldUse{"a range query", func(f *MappedField) bool {
+		return isNumeric(f) || isDate(f) || rangeTypes[f.Type] || keywordTypes[f.Type] || f.Type == "ip"
+	}}
+	useFullText = fieldUse{"a full-text query", func(f *MappedField) bool {
+		return isLeaf(f) && f.Type != "knn_vector"
+	}}
+	useKNN     = fieldUse{"a knn query", func(f *MappedField) bool { return f.Type == "knn_vector" }}
+	useNested  = fieldUse{"a nested path", func(f *MappedField) bool { return f.Type == "nested" }}
+	useNumeric = fieldUse{"a numeric value", isNumeric}
+	useMetric  = fieldUse{"a metric aggregation", func(f *MappedField) bool {
+		return isNumeric(f) || isDate(f)
+	}}
+	useBucket = fieldUse{"an aggregation", (*MappedField).aggregatable}
+	useString = fieldUse{"a string_stats aggregation", func(f *MappedField) bool {
+		return keywordTypes[f.Type]
+	}}
+	useHistogram = fieldUse{"a histogram", func(f *MappedField) bool {
+		return isNumeric(f) || isDate(f)
+	}}
+	useDateHistogram = fieldUse{"a date_histogram", isDate}
+	useIP            = fieldUse{"an ip_range aggregation", func(f *MappedField) bool { return f.Type == "ip" }}
+	useSort          = fieldUse{"a sort", (*MappedField).aggregatable}
+)
+
+// schemaChecker traverses a tree of queries and aggregations, recording the
+// problems found with its field references.
+type schemaChecker struct {
+	schema *Schema
+	errs   ValidationErrors
+
+	// nested is the path of the nested query or aggregation being checked
+	nested string
+}
+
+func (c *schemaChecker) node(node Mappable, path string) {
+	switch n := node.(type) {
+	case *SearchRequest:
+		c.node(n.query, joinPath(path, "query"))
+		c.node(n.postFilter, joinPath(path, "post_filter"))
+		c.aggs(n.aggs, joinPath(path, "aggs"))
+		for i, opt := range n.sort {
+			if f, ok := opt.(*FieldSortOption); ok {
+				c.sort(f, fmt.Sprintf("%s[%d]", joinPath(path, "sort"), i))
+			}
+		}
+	case Aggregation:
+		c.agg(n, joinPath(path, n.Name()))
+	case Mappable:
+		c.query(n, path)
+	}
+}
+
+func (c *schemaChecker) query(q Mappable, path string) {
+	switch q := q.(type) {
+	case *BoolQuery:
+		path = joinPath(path, "bool")
+		c.queries(q.must, path+".must")
+		c.queries(q.filter, path+".filter")
+		c.queries(q.mustNot, path+".must_not")
+		c.queries(q.should, path+".should")
+	case *BoostingQuery:
+		path = joinPath(path, "boosting")
+		c.query(q.Pos, path+".positive")
+		c.query(q.Neg, path+".negative")
+	case *ConstantScoreQuery:
+		c.query(q.filter, joinPath(path, "constant_score")+".filter")
+	case *DisMaxQuery:
+		c.queries(q.queries, joinPath(path, "dis_max")+".queries")
+	case *ScriptScoreQuery:
+		c.query(q.query, joinPath(path, "script_score")+".query")
+	case *FunctionScoreQuery:
+		path = joinPath(path, "function_score")
+		c.query(q.query, path+".query")
+		for i, fn := range q.functions {
+			c.query(fn.filter, fmt.Sprintf("%s.functions[%d].filter", path, i))
+		}
+	case *NestedQuery:
+		path = joinPath(path, "nested")
+		c.nestedPath(q.path, path, func() {
+			c.query(q.query, path+".query")
+		})
+
+	case *TermQuery:
+		c.field(q.field, joinPath(path, "term."+q.field), useTerm)
+		c.analyzedTerms(q.field, joinPath(path, "term."+q.field), "term query")
+	case *TermsQuery:
+		c.field(q.field, joinPath(path, "terms."+q.field), useTerm)
+		c.analyzedTerms(q.field, joinPath(path, "terms."+q.field), "terms query")
+	case *TermsSetQuery:
+		c.field(q.field, joinPath(path, "terms_set."+q.field), useTerm)
+		if q.params.MinimumShouldMatchField != "" {
+			c.field(q.params.MinimumShouldMatchField, joinPath(path, "terms_set."+q.field), useNumeric)
+		}
+	case *ExistsQuery:
+		c.field(q.Field, joinPath(path, "exists"), useExists)
+	case *PrefixQuery:
+		c.field(q.field, joinPath(path, "prefix."+q.field), usePattern)
+	case *RegexpQuery:
+		typ := "regexp"
+		if q.wildcard {
+			typ = "wildcard"
+		}
+		c.field(q.field, joinPath(path, typ+"."+q.field), usePattern)
+	case *FuzzyQuery:
+		c.field(q.field, joinPath(path, "fuzzy."+q.field), usePattern)
+	case *RangeQuery:
+		c.field(q.field, joinPath(path, "range."+q.field), useRange)
+	case *KNNQuery:
+		c.field(q.field, joinPath(path, "knn."+q.field), useKNN)
+	case *MatchQuery:
+		c.field(q.field, joinPath(path, q.mType.apiName()+"."+q.field), useFullText)
+	case *MultiMatchQuery:
+		c.fieldPatterns(q.params.Fields, joinPath(path, "multi_match"))
+	case *QueryStringQuery:
+		path = joinPath(path, "query_string")
+		c.fieldPatterns(q.params.Fields, path)
+		c.fieldPatterns([]string{q.params.DefaultField}, path)
+	case *SimpleQueryStringQuery:
+		c.fieldPatterns(q.params.Fields, joinPath(path, "simple_query_string"))
+	}
+}
+
+func (c *schemaChecker) queries(queries []Mappable, path string) {
+	for i, q := range queries {
+		c.query(q, fmt.Sprintf("%s[%d]", path, i))
+	}
+}
+
+func (c *schemaChecker) aggs(aggs []Aggregation, path string) {
+	for _, agg := range aggs {
+		if agg != nil {
+			c.agg(agg, joinPath(path, agg.Name()))
+		}
+	}
+}
+
+func (c *schemaChecker) agg(agg Aggregation, path string) {
+	switch agg := agg.(type) {
+	case *TermsAggregation:
+		c.field(agg.field, joinPath(path, "terms"), useBucket)
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *DateHistogramAggregation:
+		c.field(agg.field, joinPath(path, "date_histogram"), useDateHistogram)
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *HistogramAggregation:
+		c.field(agg.field, joinPath(path, "histogram"), useHistogram)
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *RangeAggregation:
+		use := useHistogram
+		switch agg.apiName {
+		case "date_range":
+			use = useDateHistogram
+		case "ip_range":
+			use = useIP
+		}
+		c.field(agg.field, joinPath(path, agg.apiName), use)
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *CompositeAggregation:
+		for i, src := range agg.sources {
+			use := useBucket
+			switch src.sType {
+			case "histogram":
+				use = useHistogram
+			case "date_histogram":
+				use = useDateHistogram
+			}
+			c.field(src.field, fmt.Sprintf("%s.composite.sources[%d].%s", path, i, src.name), use)
+		}
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *FilterAggregation:
+		c.query(agg.filter, joinPath(path, "filter"))
+		c.aggs(agg.aggs, joinPath(path, "aggs"))
+	case *NestedAggregation:
+		c.nestedPath(agg.path, joinPath(path, "nested"), func() {
+			c.aggs(agg.aggs, joinPath(path, "aggs"))
+		})
+
+	case *AvgAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *SumAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *MinAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *MaxAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *StatsAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *PercentilesAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useMetric)
+	case *ValueCountAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useBucket)
+	case *CardinalityAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useBucket)
+	case *StringStatsAgg:
+		c.field(agg.Field, joinPath(path, agg.apiName), useString)
+	case *WeightedAvgAgg:
+		if agg.Val != nil {
+			c.field(agg.Val.Field, joinPath(path, agg.apiName+".value"), useMetric)
+		}
+		if agg.Weig != nil {
+			c.field(agg.Weig.Field, joinPath(path, agg.apiName+".weight"), useMetric)
+		}
+	}
+}
+
+func (c *schemaChecker) sort(opt *FieldSortOption, path string) {
+	if opt.nestedPath == "" {
+		c.field(opt.field, path, useSort)
+		return
+	}
+	c.nestedPath(opt.nestedPath, path+".nested", func() {
+		c.field(opt.field, path, useSort)
+		c.query(opt.nestedFilter, path+".nested.filter")
+	})
+}
+
+// nestedPath checks the path of a nested query, aggregation or sort, then
+// calls fn to check its children within the context of that path.
+func (c *schemaChecker) nestedPath(nestedPath, path string, fn func()) {
+	c.field(nestedPath, path, useNested)
+
+	outer := c.nested
+	c.nested = nestedPath
+	fn()
+	c.nested = outer
+}
+
+// field checks a field reference, recording problems under the provided
+// path. Metadata fields, such as "_id" or "_score", are not checked.
+func (c *schemaChecker) field(name, path string, use fieldUse) {
+	if name == "" || strings.HasPrefix(name, "_") {
+		return
+	}
+
+	f, ok := c.schema.fields[name]
+	if !ok {
+		c.errs.add(path, "%s", c.unknownField(name))
+		return
+	}
+	if !use.accepts(f) {
+		reason := fmt.Sprintf("field %q of type %s cannot be used in %s", name, f.Type, use.desc)
+		if f.analyzed() {
+			if kw := c.keywordField(name); kw != "" && use.accepts(c.schema.fields[kw]) {
+				reason += fmt.Sprintf("; use %q instead", kw)
+			}
+		}
+		c.errs.add(path, "%s", reason)
+		return
+	}
+
+	if f.NestedPath != "" && f.NestedPath != c.nested && use.desc != useNested.desc {
+		c.errs.add(path, "field %q belongs to nested object %q and must be used within a nested query or aggregation", name, f.NestedPath)
+	}
+}
+
+// analyzedTerms records term and terms queries on analyzed text fields, which
+// compare the exact value provided with the tokens produced by analysis and
+// thus rarely match.
+func (c *schemaChecker) analyzedTerms(name, path, desc string) {
+	f, ok := c.schema.fields[name]
+	if !ok || !f.analyzed() {
+		return
+	}
+	reason := fmt.Sprintf("%s on analyzed field %q of type %s matches analyzed tokens rather than whole values", desc, name, f.Type)
+	if kw := c.keywordField(name); kw != "" {
+		reason += fmt.Sprintf("; use %q instead", kw)
+	}
+	c.errs.add(path, "%s", reason)
+}
+
+// fieldPatterns checks the fields of multi-field queries, which may carry a
+// boost like "title^2". Patterns with wildcards are not checked.
+func (c *schemaChecker) fieldPatterns(fields []string, path string) {
+	for _, name := range fields {
+		if i := strings.IndexByte(name, '^'); i >= 0 {
+			name = name[:i]
+		}
+		if strings.Contains(name, "*") {
+			continue
+		}
+		c.field(name, path, useFullText)
+	}
+}
+
+// keywordField returns the name of a keyword multi-field of a field, if any.
+func (c *schemaChecker) keywordField(name string) string {
+	if f, ok := c.schema.fields[name+".keyword"]; ok && keywordTypes[f.Type] {
+		return f.Name
+	}
+	for _, fieldName := range c.schema.Fields() {
+		f := c.schema.fields[fieldName]
+		if keywordTypes[f.Type] && strings.HasPrefix(fieldName, name+".") && !strings.Contains(fieldName[len(name)+1:], ".") {
+			return fieldName
+		}
+	}
+	return ""
+}
+
+// unknownField returns the reason reported for a field missing from the
+// schema, suggesting close field names.
+func (c *schemaChecker) unknownField(name string) string {
+	reason := fmt.Sprintf("unknown field %q", name)
+
+	if base, ok := strings.CutSuffix(name, ".keyword"); ok {
+		if f, ok := c.schema.fields[base]; ok {
+			if keywordTypes[f.Type] {
+				return reason + fmt.Sprintf("; %q is already a keyword field", base)
+			}
+			return reason + fmt.Sprintf("; %q of type %s has no keyword sub-field", base, f.Type)
+		}
+	}
+
+	best, bestDist := "", 3
+	for _, fieldName := range c.schema.Fields() {
+		if d := editDistance(name, fieldName); d < bestDist {
+			best, bestDist = fieldName, d
+		}
+	}
+	if best != "" {
+		reason += fmt.Sprintf("; did you mean %q?", best)
+	}
+	return reason
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
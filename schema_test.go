@@ -0,0 +1,233 @@
+package osquery
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+const testMapping = `{
+	"logs-1": {
+		"mappings": {
+			"properties": {
+				"title": {"type": "text", "fields": {"keyword": {"type": "keyword"}}},
+				"body": {"type": "text"},
+				"status": {"type": "keyword"},
+				"age": {"type": "integer"},
+				"timestamp": {"type": "date"},
+				"client_ip": {"type": "ip"},
+				"tags": {"type": "text", "fielddata": true},
+				"embedding": {"type": "knn_vector", "dimension": 3},
+				"user": {"properties": {"name": {"type": "keyword"}}},
+				"author": {"type": "alias", "path": "user.name"},
+				"comments": {
+					"type": "nested",
+					"properties": {
+						"text": {"type": "text"},
+						"likes": {"type": "long"}
+					}
+				}
+			}
+		}
+	},
+	"logs-2": {
+		"mappings": {
+			"properties": {
+				"status": {"type": "keyword"},
+				"region": {"type": "keyword"}
+			}
+		}
+	}
+}`
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := ParseMapping([]byte(testMapping))
+	assert.Nil(t, err)
+	return schema
+}
+
+func TestParseMapping(t *testing.T) {
+	schema := testSchema(t)
+
+	assert.DeepEqual(t, []string{
+		"age", "author", "body", "client_ip", "comments", "comments.likes",
+		"comments.text", "embedding", "region", "status", "tags", "timestamp",
+		"title", "title.keyword", "user", "user.name",
+	}, schema.Fields())
+
+	f, ok := schema.Field("comments.likes")
+	assert.True(t, ok)
+	assert.DeepEqual(t, &MappedField{Name: "comments.likes", Type: "long", NestedPath: "comments"}, f)
+
+	f, ok = schema.Field("author")
+	assert.True(t, ok)
+	assert.DeepEqual(t, &MappedField{Name: "author", Type: "keyword"}, f)
+
+	f, ok = schema.Field("user")
+	assert.True(t, ok)
+	assert.Equal(t, "object", f.Type)
+
+	_, ok = schema.Field("missing")
+	assert.False(t, ok)
+
+	// create index bodies and bare mappings are accepted as well
+	for _, data := range []string{
+		`{"mappings": {"properties": {"status": {"type": "keyword"}}}}`,
+		`{"properties": {"status": {"type": "keyword"}}}`,
+	} {
+		schema, err := ParseMapping([]byte(data))
+		assert.Nil(t, err)
+		assert.DeepEqual(t, []string{"status"}, schema.Fields())
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"properties": `},
+		{"not an object", `[]`},
+		{"index without mappings", `{"logs": {"settings": {}}}`},
+		{"invalid type", `{"properties": {"status": {"type": 1}}}`},
+		{"alias without path", `{"properties": {"author": {"type": "alias"}}}`},
+		{"alias to unknown field", `{"properties": {"author": {"type": "alias", "path": "user"}}}`},
+		{
+			"conflicting types",
+			`{
+				"a": {"mappings": {"properties": {"status": {"type": "keyword"}}}},
+				"b": {"mappings": {"properties": {"status": {"type": "long"}}}}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMapping([]byte(test.data))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := testSchema(t)
+
+	tests := []struct {
+		name string
+		node Mappable
+		exp  []string
+	}{
+		{
+			"valid search request",
+			Search().
+				Query(Bool().
+					Must(Match("title", "go"), MultiMatch("go").Fields("title^2", "body", "comm*")).
+					Filter(
+						Term("status", "active"),
+						Range("timestamp").Gte("now-1d"),
+						Exists("user"),
+						Prefix("title.keyword", "Go"),
+						Term("author", "kimchy"),
+					)).
+				PostFilter(Nested("comments", Range("comments.likes").Gt(10))).
+				Aggs(
+					TermsAgg("by_status", "status").Aggs(Avg("avg_age", "age")),
+					TermsAgg("by_tag", "tags"),
+					DateHistogramAgg("per_day", "timestamp").CalendarInterval("day"),
+					IPRangeAgg("ips", "client_ip").Range("10.0.0.0", "10.0.0.255"),
+					NestedAgg("comments", "comments").Aggs(Sum("likes", "comments.likes")),
+					CustomAgg("anything", map[string]interface{}{"terms": map[string]interface{}{"field": "x"}}),
+				).
+				Sort(FieldSort("_score"), FieldSort("timestamp").Order(OrderDesc)),
+			nil,
+		},
+		{
+			"unknown fields",
+			Bool().Filter(Term("stauts", "active"), Term("title.keywrd", "x"), Exists("nothing_like_it")),
+			[]string{
+				`bool.filter[0].term.stauts: unknown field "stauts"; did you mean "status"?`,
+				`bool.filter[1].term.title.keywrd: unknown field "title.keywrd"; did you mean "title.keyword"?`,
+				`bool.filter[2].exists: unknown field "nothing_like_it"`,
+			},
+		},
+		{
+			"keyword suffix mistakes",
+			Bool().Filter(Term("status.keyword", "x"), Term("body.keyword", "x")),
+			[]string{
+				`bool.filter[0].term.status.keyword: unknown field "status.keyword"; "status" is already a keyword field`,
+				`bool.filter[1].term.body.keyword: unknown field "body.keyword"; "body" of type text has no keyword sub-field`,
+			},
+		},
+		{
+			"term queries on analyzed fields",
+			Bool().Must(Term("title", "Go"), Terms("body", "a", "b")),
+			[]string{
+				`bool.must[0].term.title: term query on analyzed field "title" of type text matches analyzed tokens rather than whole values; use "title.keyword" instead`,
+				`bool.must[1].terms.body: terms query on analyzed field "body" of type text matches analyzed tokens rather than whole values`,
+			},
+		},
+		{
+			"unsuitable query types",
+			Bool().Filter(Range("title").Gte("a"), Prefix("age", "1"), KNN("title", []float64{1}).K(1), Match("user", "x")),
+			[]string{
+				`bool.filter[0].range.title: field "title" of type text cannot be used in a range query; use "title.keyword" instead`,
+				`bool.filter[1].prefix.age: field "age" of type integer cannot be used in a pattern query`,
+				`bool.filter[2].knn.title: field "title" of type text cannot be used in a knn query`,
+				`bool.filter[3].match.user: field "user" of type object cannot be used in a full-text query`,
+			},
+		},
+		{
+			"unsuitable aggregation types",
+			Search().Aggs(
+				TermsAgg("by_title", "title").Aggs(Avg("avg_status", "status")),
+				DateHistogramAgg("per_day", "age").CalendarInterval("day"),
+				Cardinality("bodies", "body"),
+			),
+			[]string{
+				`aggs.by_title.terms: field "title" of type text cannot be used in an aggregation; use "title.keyword" instead`,
+				`aggs.by_title.aggs.avg_status.avg: field "status" of type keyword cannot be used in a metric aggregation`,
+				`aggs.per_day.date_histogram: field "age" of type integer cannot be used in a date_histogram`,
+				`aggs.bodies.cardinality: field "body" of type text cannot be used in an aggregation`,
+			},
+		},
+		{
+			"nested fields",
+			Search().
+				Query(Bool().Must(Range("comments.likes").Gt(1), Nested("status", Match("comments.text", "go")))).
+				Aggs(Avg("likes", "comments.likes")).
+				Sort(FieldSort("comments.likes"), FieldSort("comments.likes").NestedPath("comments")),
+			[]string{
+				`query.bool.must[0].range.comments.likes: field "comments.likes" belongs to nested object "comments" and must be used within a nested query or aggregation`,
+				`query.bool.must[1].nested: field "status" of type keyword cannot be used in a nested path`,
+				`query.bool.must[1].nested.query.match.comments.text: field "comments.text" belongs to nested object "comments" and must be used within a nested query or aggregation`,
+				`aggs.likes.avg: field "comments.likes" belongs to nested object "comments" and must be used within a nested query or aggregation`,
+				`sort[0]: field "comments.likes" belongs to nested object "comments" and must be used within a nested query or aggregation`,
+			},
+		},
+		{
+			"sort on text field",
+			Search().Sort(FieldSort("body")),
+			[]string{`sort[0]: field "body" of type text cannot be used in a sort`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate(test.node)
+			if test.exp == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var errs ValidationErrors
+			assert.True(t, errors.As(err, &errs))
+			msgs := make([]string, len(errs))
+			for i, err := range errs {
+				msgs[i] = err.Error()
+			}
+			assert.DeepEqual(t, test.exp, msgs)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package osquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// ScrollIterator pages through all hits of a search request using the scroll
+// API, as described in
+// https://opensearch.org/docs/latest/api-reference/scroll/
+// The source of each hit is decoded into T. The scroll is started when the
+// first page is fetched, and cleared once all hits have been returned, an
+// error occurs or the context is cancelled.
+//
+//	it := osquery.IterateScroll[Doc](req, client, time.Minute, &osquery.SearchOptions{
+//	    Indices: []string{"logs"},
+//	})
+//	defer it.Close(ctx)
+//	for it.Next(ctx) {
+//	    doc := it.Hit().Source
+//	    // ...
+//	}
+//	if err := it.Err(); err != nil {
+//	    // ...
+//	}
+type ScrollIterator[T any] struct {
+	req       *SearchRequest
+	client    *opensearch.Client
+	options   *SearchOptions
+	keepAlive time.Duration
+
+	scrollID string
+	started  bool
+	hits     []Hit[T]
+	hit      Hit[T]
+	done     bool
+	err      error
+}
+
+// defaultScrollKeepAlive is how long scroll contexts are kept alive between
+// pages when no positive keep-alive duration is provided.
+const defaultScrollKeepAlive = time.Minute
+
+// IterateScroll returns an iterator over all hits of the provided search
+// request, keeping the scroll context alive for the provided duration between
+// pages, or for a minute if it is not positive. The size of the request sets
+// the number of hits per page.
+func IterateScroll[T any](
+	req *SearchRequest,
+	client *opensearch.Client,
+	keepAlive time.Duration,
+	options *SearchOptions,
+) *ScrollIterator[T] {
+	if keepAlive <= 0 {
+		keepAlive = defaultScrollKeepAlive
+	}
+	return &ScrollIterator[T]{
+		req:       req,
+		client:    client,
+		options:   options,
+		keepAlive: keepAlive,
+	}
+}
+
+// Next advances the iterator to the next hit, fetching the next page of hits
+// if necessary. It returns false when all hits have been returned or an error
+// occurred, which is then available through Err.
+func (it *ScrollIterator[T]) Next(ctx context.Context) bool {
+	for len(it.hits) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch(ctx)
+	}
+
+	it.hit = it.hits[0]
+	it.hits = it.hits[1:]
+	return true
+}
+
+// fetch starts the scroll, or retrieves its next page of hits.
+func (it *ScrollIterator[T]) fetch(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		it.fail(ctx, err)
+		return
+	}
+
+	var res *SearchResult[T]
+	var err error
+	if !it.started {
+		it.started = true
+		res, err = RunInto[T](ctx, it.req, it.client, it.scrollOptions())
+	} else {
+		res, err = it.scroll(ctx)
+	}
+	if err != nil {
+		it.fail(ctx, err)
+		return
+	}
+	if res.ScrollID != "" {
+		it.scrollID = res.ScrollID
+	}
+
+	it.hits = res.Hits.Hits
+	if len(it.hits) == 0 {
+		it.finish(ctx)
+	}
+}
+
+// scrollOptions returns the options of the iterator, with the "scroll"
+// parameter set to the keep-alive duration.
+func (it *ScrollIterator[T]) scrollOptions() *SearchOptions {
+	var opts SearchOptions
+	if it.options != nil {
+		opts = *it.options
+	}
+
+	var params opensearchapi.SearchParams
+	if opts.Params != nil {
+		params = *opts.Params
+	}
+	params.Scroll = it.keepAlive
+	opts.Params = &params
+
+	return &opts
+}
+
+// scroll retrieves the next page of hits of the scroll.
+func (it *ScrollIterator[T]) scroll(ctx context.Context) (*SearchResult[T], error) {
+	req := opensearchapi.ScrollGetReq{
+		ScrollID: it.scrollID,
+		Params: opensearchapi.ScrollGetParams{
+			Scroll: it.keepAlive,
+		},
+	}
+	if it.options != nil {
+		req.Header = it.options.Header
+	}
+
+	var res SearchResult[T]
+	if err := execute(ctx, it.client, req, &res); err != nil {
+		return nil, fmt.Errorf("scroll request failed: %w", err)
+	}
+
+	return &res, nil
+}
+
+// finish marks the iterator as done and clears the scroll.
+func (it *ScrollIterator[T]) finish(ctx context.Context) {
+	it.done = true
+	if err := it.release(ctx); err != nil && it.err == nil {
+		it.err = err
+	}
+}
+
+// fail records the provided error and clears the scroll. Errors clearing the
+// scroll are ignored in favor of the original one.
+func (it *ScrollIterator[T]) fail(ctx context.Context, err error) {
+	it.err = err
+	_ = it.release(ctx)
+}
+
+// release clears the scroll if it is still open. The scroll is cleared even if
+// the provided context has been cancelled.
+func (it *ScrollIterator[T]) release(ctx context.Context) error {
+	if it.scrollID == "" {
+		return nil
+	}
+	scrollID := it.scrollID
+	it.scrollID = ""
+
+	// the scroll ID is sent in the body as it can be too long for the path
+	body, err := json.Marshal(map[string][]string{"scroll_id": {scrollID}})
+	if err != nil {
+		return err
+	}
+	req := opensearchapi.ScrollDeleteReq{
+		Body: bytes.NewReader(body),
+	}
+
+	var res opensearchapi.ScrollDeleteResp
+	if err := execute(context.WithoutCancel(ctx), it.client, req, &res); err != nil {
+		return fmt.Errorf("clear scroll request failed: %w", err)
+	}
+
+	return nil
+}
+
+// Hit returns the current hit.
+func (it *ScrollIterator[T]) Hit() Hit[T] {
+	return it.hit
+}
+
+// ScrollID returns the identifier of the scroll, or an empty string if it has
+// not been started yet or has already been cleared.
+func (it *ScrollIterator[T]) ScrollID() string {
+	return it.scrollID
+}
+
+// Err returns the first error encountered by the iterator, if any.
+func (it *ScrollIterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and clears the scroll if it is still open. It is
+// safe to call Close multiple times, and after the iteration has finished.
+func (it *ScrollIterator[T]) Close(ctx context.Context) error {
+	it.done = true
+	it.hits = nil
+	return it.release(ctx)
+}
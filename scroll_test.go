@@ -0,0 +1,129 @@
+package osquery
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+const testScrollDeleteResponse = `{"succeeded": true, "num_freed": 1}`
+
+func TestScrollIterator(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"_scroll_id": "scroll-1", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}},
+			{"_id": "2", "_source": {"title": "two"}}
+		]}}`,
+		`{"_scroll_id": "scroll-2", "hits": {"hits": [
+			{"_id": "3", "_source": {"title": "three"}}
+		]}}`,
+		`{"_scroll_id": "scroll-2", "hits": {"hits": []}}`,
+		testScrollDeleteResponse,
+	)
+
+	ctx := context.Background()
+	it := IterateScroll[testDoc](Query(MatchAll()).Size(2), client, time.Minute, &SearchOptions{
+		Indices: []string{"posts"},
+		Header:  http.Header{"X-Opaque-Id": []string{"export"}},
+		Params:  &opensearchapi.SearchParams{Routing: []string{"user1"}},
+	})
+
+	var titles []string
+	for it.Next(ctx) {
+		titles = append(titles, it.Hit().Source.Title)
+	}
+	assert.Nil(t, it.Err())
+	assert.DeepEqual(t, []string{"one", "two", "three"}, titles)
+	assert.Equal(t, "", it.ScrollID())
+	assert.Nil(t, it.Close(ctx))
+
+	assert.Equal(t, 4, len(*requests))
+	assert.Equal(t, "/posts/_search", (*requests)[0].path)
+	assert.Equal(t, "routing=user1&scroll=60000ms", (*requests)[0].query)
+	assert.Equal(t, `{"query":{"match_all":{}},"size":2}`, (*requests)[0].body)
+	assert.Equal(t, "/_search/scroll", (*requests)[1].path)
+	assert.Equal(t, `{"scroll_id":"scroll-1"}`, (*requests)[1].body)
+	assert.Equal(t, `{"scroll_id":"scroll-2"}`, (*requests)[2].body)
+	assert.Equal(t, "DELETE", (*requests)[3].method)
+	assert.Equal(t, `{"scroll_id":["scroll-2"]}`, (*requests)[3].body)
+}
+
+func TestScrollIteratorClose(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"_scroll_id": "scroll-1", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}},
+			{"_id": "2", "_source": {"title": "two"}}
+		]}}`,
+		testScrollDeleteResponse,
+	)
+
+	ctx := context.Background()
+	it := IterateScroll[testDoc](Search(), client, time.Minute, nil)
+
+	assert.True(t, it.Next(ctx))
+	assert.Equal(t, "scroll-1", it.ScrollID())
+	assert.Nil(t, it.Close(ctx))
+	assert.False(t, it.Next(ctx))
+	assert.Nil(t, it.Err())
+
+	assert.Equal(t, 2, len(*requests))
+	assert.Equal(t, "DELETE", (*requests)[1].method)
+}
+
+func TestScrollIteratorCancel(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"_scroll_id": "scroll-1", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}}
+		]}}`,
+		testScrollDeleteResponse,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := IterateScroll[testDoc](Search().Size(1), client, time.Minute, nil)
+
+	assert.True(t, it.Next(ctx))
+	cancel()
+	assert.False(t, it.Next(ctx))
+	assert.Equal(t, context.Canceled, it.Err())
+
+	assert.Equal(t, 2, len(*requests))
+	assert.Equal(t, `{"scroll_id":["scroll-1"]}`, (*requests)[1].body)
+}
+
+func TestScrollIteratorError(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"_scroll_id": "scroll-1", "hits": {"hits": [
+			{"_id": "1", "_source": {"title": "one"}}
+		]}}`,
+		`{"error": {"type": "search_context_missing_exception", "reason": "no search context found"}, "status": 404}`,
+		testScrollDeleteResponse,
+	)
+
+	ctx := context.Background()
+	it := IterateScroll[testDoc](Search().Size(1), client, time.Minute, nil)
+
+	assert.True(t, it.Next(ctx))
+	assert.False(t, it.Next(ctx))
+	assert.NotNil(t, it.Err())
+
+	assert.Equal(t, 3, len(*requests))
+	assert.Equal(t, "DELETE", (*requests)[2].method)
+}
+
+func TestScrollIteratorNonPositiveKeepAlive(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"_scroll_id": "scroll-1", "hits": {"hits": []}}`,
+		testScrollDeleteResponse,
+	)
+
+	ctx := context.Background()
+	it := IterateScroll[testDoc](Search(), client, 0, nil)
+
+	assert.False(t, it.Next(ctx))
+	assert.Nil(t, it.Err())
+	assert.Equal(t, "scroll=60000ms", (*requests)[0].query)
+}
@@ -3,8 +3,9 @@ package osquery
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"time"
 
 	"github.com/opensearch-project/opensearch-go/v4"
@@ -25,6 +26,14 @@ type SearchRequest struct {
 	source       Source
 	timeout      *time.Duration
 	scriptFields []*ScriptField
+	pit          *pitParams
+	shortForm    bool
+	custom       map[string]interface{}
+}
+
+type pitParams struct {
+	id        string
+	keepAlive time.Duration
 }
 
 // Search creates a new SearchRequest object, to be filled via method chaining.
@@ -85,6 +94,14 @@ func (req *SearchRequest) Explain(b bool) *SearchRequest {
 	return req
 }
 
+// ShortForm sets whether the request is rendered using the compact forms of
+// its queries, as described in ShortMap. This affects Map, MarshalJSON and the
+// body sent by Run.
+func (req *SearchRequest) ShortForm(b bool) *SearchRequest {
+	req.shortForm = b
+	return req
+}
+
 // Timeout sets a timeout for the request.
 func (req *SearchRequest) Timeout(dur time.Duration) *SearchRequest {
 	req.timeout = &dur
@@ -115,13 +132,38 @@ func (req *SearchRequest) Highlight(highlight Mappable) *SearchRequest {
 	return req
 }
 
+// PIT sets the point in time to run the request against, extending its
+// lifetime by the provided keep-alive duration. Requests using a point in time
+// must not target any index.
+func (req *SearchRequest) PIT(id string, keepAlive time.Duration) *SearchRequest {
+	req.pit = &pitParams{id: id, keepAlive: keepAlive}
+	return req
+}
+
 func (req *SearchRequest) ScriptFields(fields ...*ScriptField) *SearchRequest {
 	req.scriptFields = append(req.scriptFields, fields...)
 	return req
 }
 
+// Custom sets a top-level field of the request body that the library does not
+// support, such as "track_total_hits" or "collapse". The value is added to the
+// body as is, unless the request sets the same field itself.
+func (req *SearchRequest) Custom(key string, value interface{}) *SearchRequest {
+	if req.custom == nil {
+		req.custom = make(map[string]interface{})
+	}
+	req.custom[key] = value
+	return req
+}
+
 // Map converts the SearchRequest to a map for the body.
 func (req *SearchRequest) Map() map[string]interface{} {
+	if req.shortForm {
+		c := *req
+		c.shortForm = false
+		return ShortMap(&c)
+	}
+
 	m := make(map[string]interface{})
 	if req.query != nil {
 		m["query"] = req.query.Map()
@@ -161,6 +203,15 @@ func (req *SearchRequest) Map() map[string]interface{} {
 	if req.searchAfter != nil {
 		m["search_after"] = req.searchAfter
 	}
+	if req.pit != nil {
+		pit := map[string]interface{}{
+			"id": req.pit.id,
+		}
+		if req.pit.keepAlive != 0 {
+			pit["keep_alive"] = formatKeepAlive(req.pit.keepAlive)
+		}
+		m["pit"] = pit
+	}
 
 	if len(req.scriptFields) > 0 {
 		scripts := make(map[string]interface{})
@@ -173,50 +224,207 @@ func (req *SearchRequest) Map() map[string]interface{} {
 	if len(source) > 0 {
 		m["_source"] = source
 	}
+	for key, value := range req.custom {
+		if _, ok := m[key]; !ok {
+			m[key] = value
+		}
+	}
 
 	return m
 }
 
+// Validate checks the query, post filter and aggregations of the request for
+// problems that would make OpenSearch reject it. It returns a ValidationErrors
+// value listing all the problems found, or nil. Run calls Validate before
+// sending the request.
+func (req *SearchRequest) Validate() error {
+	return validate(req, "")
+}
+
+func (req *SearchRequest) validate(path string, errs *ValidationErrors) {
+	if req.query != nil {
+		validateNode(req.query, joinPath(path, "query"), errs)
+	}
+	if req.postFilter != nil {
+		validateNode(req.postFilter, joinPath(path, "post_filter"), errs)
+	}
+	validateAggs(req.aggs, joinPath(path, "aggs"), errs)
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (req *SearchRequest) MarshalJSON() ([]byte, error) {
-	return json.Marshal(req.Map())
+	return marshalJSON(req)
+}
+
+// WriteJSON writes the JSON encoding of the request to w, thus implementing
+// the JSONWriter interface.
+func (req *SearchRequest) WriteJSON(w io.Writer) error {
+	return writeJSON(w, req)
+}
+
+func (req *SearchRequest) encodeJSON(e *encoder) {
+	if req.shortForm || len(req.custom) > 0 {
+		e.value(req.Map())
+		return
+	}
+
+	e.openObject()
+	if source := req.source.Map(); len(source) > 0 {
+		e.key("_source")
+		e.value(source)
+	}
+	if len(req.aggs) > 0 {
+		// later aggregations replace earlier ones of the same name, as in Map
+		aggs := make(map[string]Mappable, len(req.aggs))
+		for _, agg := range req.aggs {
+			aggs[agg.Name()] = agg
+		}
+		e.key("aggs")
+		encodeNamed(e, aggs)
+	}
+	if req.explain != nil {
+		e.key("explain")
+		e.boolean(*req.explain)
+	}
+	if req.from != nil {
+		e.key("from")
+		e.uint(*req.from)
+	}
+	if req.highlight != nil {
+		e.key("highlight")
+		e.mappable(req.highlight)
+	}
+	if req.pit != nil {
+		e.key("pit")
+		e.openObject()
+		e.key("id")
+		e.str(req.pit.id)
+		if req.pit.keepAlive != 0 {
+			e.key("keep_alive")
+			e.str(formatKeepAlive(req.pit.keepAlive))
+		}
+		e.closeObject()
+	}
+	if req.postFilter != nil {
+		e.key("post_filter")
+		e.mappable(req.postFilter)
+	}
+	if req.query != nil {
+		e.key("query")
+		e.mappable(req.query)
+	}
+	if len(req.scriptFields) > 0 {
+		scripts := make(map[string]Mappable, len(req.scriptFields))
+		for _, script := range req.scriptFields {
+			scripts[script.Name()] = script
+		}
+		e.key("script_fields")
+		encodeNamed(e, scripts)
+	}
+	if req.searchAfter != nil {
+		e.key("search_after")
+		e.value(req.searchAfter)
+	}
+	if req.size != nil {
+		e.key("size")
+		e.uint(*req.size)
+	}
+	if len(req.sort) > 0 {
+		e.key("sort")
+		e.openArray()
+		for _, params := range req.sort {
+			e.value(params.Map())
+		}
+		e.closeArray()
+	}
+	if req.timeout != nil {
+		e.key("timeout")
+		e.str(fmt.Sprintf("%.0fs", req.timeout.Seconds()))
+	}
+	e.closeObject()
+}
+
+// encodeNamed writes an object of named queries, aggregations or scripts, in
+// name order.
+func encodeNamed(e *encoder, nodes map[string]Mappable) {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e.openObject()
+	for _, name := range names {
+		e.key(name)
+		e.mappable(nodes[name])
+	}
+	e.closeObject()
 }
 
 // Run executes the search using the OpenSearch client, applying additional options.
 func (req *SearchRequest) Run(
 	ctx context.Context,
 	client *opensearch.Client,
-	options *Options,
+	options *SearchOptions,
 ) (*opensearchapi.SearchResp, error) {
+	var searchResp opensearchapi.SearchResp
+	if err := req.run(ctx, client, options, &searchResp); err != nil {
+		return nil, err
+	}
+
+	return &searchResp, nil
+}
+
+// run serializes the request, applies the provided options and executes it
+// against the "_search" endpoint, decoding the response body into out.
+func (req *SearchRequest) run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *SearchOptions,
+	out interface{},
+) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid search request: %w", err)
+	}
+
 	// Serialize the request body to JSON
-	body, err := json.Marshal(req.Map())
+	body, err := marshalJSON(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize request body: %w", err)
+		return fmt.Errorf("failed to serialize request body: %w", err)
 	}
 
-	// Create a Search request, setting size to 0 to avoid fetching documents
 	searchReq := opensearchapi.SearchReq{
 		Body: bytes.NewReader(body),
 	}
 
 	// Apply additional options if provided
-	err = ApplyOptions(&searchReq, options)
-	if err != nil {
-		return nil, err
-	}
+	options.Apply(&searchReq.Indices, &searchReq.Header, &searchReq.Params)
 
-	// fmt.Printf("%+v", searchReq)
+	if err := execute(ctx, client, searchReq, out); err != nil {
+		return fmt.Errorf("search request failed: %w", err)
+	}
 
-	// Create a variable to hold the response
-	var searchResp opensearchapi.SearchResp
+	return nil
+}
 
-	// Execute the search request using the OpenSearch client's Do method
-	if _, err := client.Do(ctx, searchReq, &searchResp); err != nil {
-		return nil, fmt.Errorf("search request failed: %w", err)
+// execute performs the provided request using the OpenSearch client's Do
+// method, decoding a successful response into out. Responses with an error
+// status code are converted into errors.
+func execute(
+	ctx context.Context,
+	client *opensearch.Client,
+	req opensearch.Request,
+	out interface{},
+) error {
+	res, err := client.Do(ctx, req, out)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return opensearch.ParseError(res)
 	}
 
-	// Return the parsed response
-	return &searchResp, nil
+	return nil
 }
 
 // Query is a shortcut for creating a SearchRequest with only a query. It is
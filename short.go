@@ -0,0 +1,80 @@
+package osquery
+
+import "encoding/json"
+
+// ShortMap returns a map representation of a query, aggregation or search
+// request, as Map does, but using the compact forms OpenSearch accepts where
+// they are equivalent to the full ones:
+//
+//   - term and prefix queries with no parameter other than their value are
+//     written as {"term": {"user": "kimchy"}} rather than
+//     {"term": {"user": {"value": "kimchy"}}};
+//   - match, match_phrase, match_phrase_prefix and match_bool_prefix queries
+//     with no parameter other than their query text are written as
+//     {"match": {"title": "go"}};
+//   - clauses of bool queries holding a single query are written as that
+//     query's object rather than an array.
+//
+// Queries are shortened wherever they appear in the tree, as traversed by
+// Walk. Custom queries and aggregations are left as-is.
+func ShortMap(node Mappable) map[string]interface{} {
+	if node == nil {
+		return nil
+	}
+
+	// shorten never removes a node nor replaces an aggregation, so Transform
+	// can only return nil for trees missing a required child
+	short, _ := Transform(node, shorten)
+	if short == nil {
+		return node.Map()
+	}
+	return short.Map()
+}
+
+// MarshalShort returns the JSON encoding of a query, aggregation or search
+// request, using the compact forms described in ShortMap.
+func MarshalShort(node Mappable) ([]byte, error) {
+	return json.Marshal(ShortMap(node))
+}
+
+// shorten replaces queries that have a compact form by a custom query holding
+// it.
+func shorten(node Mappable) Mappable {
+	switch node.(type) {
+	case *TermQuery, *PrefixQuery:
+		return shortenFieldQuery(node.Map(), "value")
+	case *MatchQuery:
+		return shortenFieldQuery(node.Map(), "query")
+	case *BoolQuery:
+		m := node.Map()
+		params := m["bool"].(map[string]interface{})
+		for _, clause := range []string{"must", "filter", "must_not", "should"} {
+			if queries, ok := params[clause].([]map[string]interface{}); ok && len(queries) == 1 {
+				params[clause] = queries[0]
+			}
+		}
+		return CustomQuery(m)
+	}
+	return node
+}
+
+// shortenFieldQuery collapses a query of the form
+// {"type": {"field": {key: value}}} into {"type": {"field": value}}, provided
+// key is its only parameter and value is not an object, which OpenSearch would
+// take for the full form.
+func shortenFieldQuery(m map[string]interface{}, key string) Mappable {
+	for _, body := range m {
+		fields := body.(map[string]interface{})
+		for field, params := range fields {
+			p, ok := params.(map[string]interface{})
+			if !ok || len(p) != 1 {
+				continue
+			}
+			value, ok := p[key]
+			if _, isObject := value.(map[string]interface{}); ok && !isObject {
+				fields[field] = value
+			}
+		}
+	}
+	return CustomQuery(m)
+}
@@ -0,0 +1,112 @@
+package osquery
+
+import (
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestShortMap(t *testing.T) {
+	tests := []struct {
+		name string
+		node Mappable
+		exp  string
+	}{
+		{
+			"term query",
+			Term("user", "kimchy"),
+			`{"term": {"user": "kimchy"}}`,
+		},
+		{
+			"term query with parameters",
+			Term("user", "kimchy").Boost(2),
+			`{"term": {"user": {"value": "kimchy", "boost": 2}}}`,
+		},
+		{
+			"prefix query",
+			Prefix("user", "ki"),
+			`{"prefix": {"user": "ki"}}`,
+		},
+		{
+			"match phrase query",
+			MatchPhrase("title", "go and stuff"),
+			`{"match_phrase": {"title": "go and stuff"}}`,
+		},
+		{
+			"match query with operator",
+			Match("title", "go").Operator(OperatorAnd),
+			`{"match": {"title": {"query": "go", "operator": "AND"}}}`,
+		},
+		{
+			"bool query",
+			Bool().
+				Must(Term("tag", "tech")).
+				Filter(Term("status", "active"), Range("age").Gte(18)).
+				MinimumShouldMatch(1),
+			`{"bool": {
+				"must": {"term": {"tag": "tech"}},
+				"filter": [
+					{"term": {"status": "active"}},
+					{"range": {"age": {"gte": 18}}}
+				],
+				"minimum_should_match": 1
+			}}`,
+		},
+		{
+			"nested compound queries",
+			ConstantScore(Nested("comments", Bool().Should(Match("comments.text", "go")))),
+			`{"constant_score": {"filter": {"nested": {
+				"path": "comments",
+				"query": {"bool": {"should": {"match": {"comments.text": "go"}}}}
+			}}}}`,
+		},
+		{
+			"custom query",
+			CustomQuery(map[string]interface{}{
+				"term": map[string]interface{}{
+					"user": map[string]interface{}{"value": "kimchy"},
+				},
+			}),
+			`{"term": {"user": {"value": "kimchy"}}}`,
+		},
+		{
+			"search request",
+			Search().
+				Query(Bool().Must(Term("user", "kimchy"))).
+				Aggs(FilterAgg("go", Match("title", "go")).Aggs(Avg("avg_score", "score"))).
+				Size(10),
+			`{
+				"query": {"bool": {"must": {"term": {"user": "kimchy"}}}},
+				"aggs": {"go": {
+					"filter": {"match": {"title": "go"}},
+					"aggs": {"avg_score": {"avg": {"field": "score"}}}
+				}},
+				"size": 10
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertJSON(t, test.exp, ShortMap(test.node))
+		})
+	}
+}
+
+func TestSearchRequestShortForm(t *testing.T) {
+	q := Bool().Filter(Term("user", "kimchy"))
+	req := Search().Query(q).ShortForm(true)
+
+	assertJSON(t, `{"query": {"bool": {"filter": {"term": {"user": "kimchy"}}}}}`, req.Map())
+	assertJSON(t, `{"bool": {"filter": [{"term": {"user": {"value": "kimchy"}}}]}}`, q.Map())
+
+	body, err := req.MarshalJSON()
+	assert.Nil(t, err)
+	short, err := MarshalShort(Search().Query(q))
+	assert.Nil(t, err)
+	assert.Equal(t, string(short), string(body))
+
+	parsed, err := ParseSearchRequest(body)
+	assert.Nil(t, err)
+	assertJSON(t, `{"query": {"bool": {"filter": [{"term": {"user": {"value": "kimchy"}}}]}}}`, parsed.Map())
+}
@@ -0,0 +1,309 @@
+package osquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// JSONWriter is implemented by the types that can write their JSON encoding
+// directly to a writer, without building the map returned by their Map
+// method. The output is the same as the JSON encoding of that map.
+type JSONWriter interface {
+	WriteJSON(w io.Writer) error
+}
+
+// WriteJSON writes the JSON encoding of a query, aggregation or search request
+// to w. Types implementing JSONWriter are written directly, as are the
+// children of compound queries implementing it; other types are encoded from
+// the map returned by their Map method.
+func WriteJSON(w io.Writer, node Mappable) error {
+	e := newEncoder()
+	defer e.release()
+
+	e.mappable(node)
+	return e.writeTo(w)
+}
+
+// jsonEncodable is implemented by the types that write their JSON encoding
+// to an encoder. They implement JSONWriter by calling writeJSON.
+type jsonEncodable interface {
+	encodeJSON(e *encoder)
+}
+
+// writeJSON writes the JSON encoding of node to w.
+func writeJSON(w io.Writer, node jsonEncodable) error {
+	e := newEncoder()
+	defer e.release()
+
+	node.encodeJSON(e)
+	return e.writeTo(w)
+}
+
+// marshalJSON returns the JSON encoding of node.
+func marshalJSON(node jsonEncodable) ([]byte, error) {
+	e := newEncoder()
+	defer e.release()
+
+	node.encodeJSON(e)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return append([]byte(nil), e.buf...), nil
+}
+
+//----------------------------------------------------------------------------//
+
+// encoder writes JSON to a buffer. Separators between object members and
+// array elements are written automatically. The first error encountered is
+// kept in err, and the output is then invalid.
+type encoder struct {
+	buf []byte
+	err error
+}
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return &encoder{buf: make([]byte, 0, 1024)}
+	},
+}
+
+func newEncoder() *encoder {
+	return encoderPool.Get().(*encoder)
+}
+
+// release returns the encoder to the pool. Overly large buffers are dropped
+// so that a single large request does not keep its memory around.
+func (e *encoder) release() {
+	if cap(e.buf) > 64*1024 {
+		return
+	}
+	e.buf = e.buf[:0]
+	e.err = nil
+	encoderPool.Put(e)
+}
+
+func (e *encoder) writeTo(w io.Writer) error {
+	if e.err != nil {
+		return e.err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// sep writes the separator preceding a value or member, if needed.
+func (e *encoder) sep() {
+	if n := len(e.buf); n > 0 {
+		switch e.buf[n-1] {
+		case '{', '[', ':':
+		default:
+			e.buf = append(e.buf, ',')
+		}
+	}
+}
+
+func (e *encoder) openObject() {
+	e.sep()
+	e.buf = append(e.buf, '{')
+}
+
+func (e *encoder) closeObject() {
+	e.buf = append(e.buf, '}')
+}
+
+func (e *encoder) openArray() {
+	e.sep()
+	e.buf = append(e.buf, '[')
+}
+
+func (e *encoder) closeArray() {
+	e.buf = append(e.buf, ']')
+}
+
+// key writes the key of an object member, whose value must be written next.
+func (e *encoder) key(k string) {
+	e.str(k)
+	e.buf = append(e.buf, ':')
+}
+
+func (e *encoder) str(s string) {
+	e.sep()
+	for i := 0; i < len(s); i++ {
+		// leave escaping to encoding/json, along with HTML characters and
+		// non-ASCII ones, which it may escape as well
+		if c := s[i]; c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			data, _ := json.Marshal(s)
+			e.buf = append(e.buf, data...)
+			return
+		}
+	}
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, '"')
+}
+
+func (e *encoder) boolean(b bool) {
+	e.sep()
+	e.buf = strconv.AppendBool(e.buf, b)
+}
+
+func (e *encoder) int(i int64) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, i, 10)
+}
+
+func (e *encoder) uint(u uint64) {
+	e.sep()
+	e.buf = strconv.AppendUint(e.buf, u, 10)
+}
+
+// float writes a floating-point number of the provided bit size the way
+// encoding/json does.
+func (e *encoder) float(f float64, bits int) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		if e.err == nil {
+			e.err = fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+		}
+		return
+	}
+
+	e.sep()
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+}
+
+func (e *encoder) null() {
+	e.sep()
+	e.buf = append(e.buf, "null"...)
+}
+
+func (e *encoder) strings(list []string) {
+	if list == nil {
+		e.null()
+		return
+	}
+	e.openArray()
+	for _, s := range list {
+		e.str(s)
+	}
+	e.closeArray()
+}
+
+func (e *encoder) floats(list []float64) {
+	if list == nil {
+		e.null()
+		return
+	}
+	e.openArray()
+	for _, f := range list {
+		e.float(f, 64)
+	}
+	e.closeArray()
+}
+
+// mappable writes a query or aggregation, directly if it supports it.
+func (e *encoder) mappable(m Mappable) {
+	switch m := m.(type) {
+	case nil:
+		e.null()
+	case jsonEncodable:
+		m.encodeJSON(e)
+	default:
+		e.value(m.Map())
+	}
+}
+
+// value writes an arbitrary value, such as the value of a term query. Common
+// types are written directly, others are encoded by encoding/json.
+func (e *encoder) value(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		e.null()
+	case string:
+		e.str(v)
+	case bool:
+		e.boolean(v)
+	case int:
+		e.int(int64(v))
+	case int8:
+		e.int(int64(v))
+	case int16:
+		e.int(int64(v))
+	case int32:
+		e.int(int64(v))
+	case int64:
+		e.int(v)
+	case uint:
+		e.uint(uint64(v))
+	case uint8:
+		e.uint(uint64(v))
+	case uint16:
+		e.uint(uint64(v))
+	case uint32:
+		e.uint(uint64(v))
+	case uint64:
+		e.uint(v)
+	case float32:
+		e.float(float64(v), 32)
+	case float64:
+		e.float(v, 64)
+	case []string:
+		e.strings(v)
+	case []float64:
+		e.floats(v)
+	case []interface{}:
+		if v == nil {
+			e.null()
+			return
+		}
+		e.openArray()
+		for _, elem := range v {
+			e.value(elem)
+		}
+		e.closeArray()
+	case map[string]interface{}:
+		if v == nil {
+			e.null()
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		e.openObject()
+		for _, key := range keys {
+			e.key(key)
+			e.value(v[key])
+		}
+		e.closeObject()
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			if e.err == nil {
+				e.err = err
+			}
+			return
+		}
+		e.sep()
+		e.buf = append(e.buf, data...)
+	}
+}
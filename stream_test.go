@@ -0,0 +1,188 @@
+package osquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		node Mappable
+	}{
+		{"exists", Exists("title")},
+		{"ids", IDs("1", "2")},
+		{"ids without values", IDs()},
+		{"prefix", Prefix("user", "ki").Rewrite("constant_score")},
+		{"range", Range("age").Gte(18).Lt(65.5).Format("yyyy").Relation(RangeWithin).TimeZone("UTC").Boost(1.1)},
+		{"range with zero bound", Range("count").Gt(0)},
+		{"regexp", Regexp("path", "/a.*").Flags("ALL").MaxDeterminizedStates(100).Rewrite("scoring_boolean")},
+		{"wildcard", Wildcard("user", "ki*y")},
+		{"fuzzy", Fuzzy("user", "ki").Fuzziness("AUTO").MaxExpansions(5).PrefixLength(1).Transpositions(false).Rewrite("top_terms_10")},
+		{"term", Term("user", "kimchy").Boost(2.5).CaseInsensitive(true).Name("by_user")},
+		{"term with escaped value", Term("html", "<a href=\"x\">\n é</a>")},
+		{"term with time value", Term("@timestamp", time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC))},
+		{"term with tiny and huge values", Terms("n", 1e-7, float32(3e21), uint8(3), int16(-4))},
+		{"terms", Terms("tags", "go", 1, true, nil).Boost(0.5)},
+		{"terms on boost sorted after field", Terms("another", "x").Boost(2)},
+		{"terms on boost sorted before field", Terms("tags", "x").Boost(2)},
+		{"terms set", TermsSet("codes", "a", "b").MinimumShouldMatchField("required").MinimumShouldMatchScript("params.n")},
+		{"knn", KNN("embedding", []float64{0.1, -2.5, 3, 1e-9}).K(10)},
+		{
+			"knn with all parameters",
+			KNN("embedding", []float64{1}).
+				MaxDistance(0.5).
+				MinScore(0.8).
+				Filter(map[string]interface{}{"term": map[string]interface{}{"tag": "go"}}).
+				MethodParameters(map[string]interface{}{"ef_search": 100}).
+				Rescore(map[string]interface{}{"oversample_factor": 1.5}).
+				ExpandNestedDocs(true),
+		},
+		{
+			"nested",
+			Nested("comments", Bool().Must(Term("comments.author", "kimchy"))).
+				ScoreMode(ScoreModeMax).
+				InnerHits(map[string]interface{}{"size": 3}),
+		},
+		{
+			"bool",
+			Bool().
+				Must(Term("user", "kimchy"), Match("title", "go")).
+				Filter(Range("age").Gte(18)).
+				MustNot(Exists("deleted")).
+				Should(KNN("embedding", []float64{1, 2}).K(2)).
+				MinimumShouldMatch(1).
+				Boost(1.5).
+				Name("main"),
+		},
+		{
+			"highlight",
+			Highlight().
+				PreTags("<em>").
+				PostTags("</em>").
+				Field("title", Highlight().FragmentSize(50).Type(HighlighterFvh)).
+				Field("body").
+				Encoder(EncoderHtml).
+				Order(OrderScore).
+				TagsSchema(TagsSchemaStyled).
+				BoundaryScanner(BoundaryScannerWord).
+				Fragmenter(FragmenterSimple).
+				RequireFieldMatch(false).
+				HighlightQuery(Term("title", "go")),
+		},
+		{
+			"search request",
+			Search().
+				Query(Bool().Filter(Term("user", "kimchy"))).
+				PostFilter(Terms("tags", "go")).
+				Aggs(TermsAgg("tags", "tags"), Avg("avg_score", "score"), Max("tags", "score")).
+				Size(10).
+				From(20).
+				Explain(true).
+				Timeout(5*time.Second).
+				SearchAfter("abc", 3).
+				Sort(FieldSort("date").Order(OrderDesc)).
+				SourceIncludes("title").
+				Highlight(Highlight().Field("title")).
+				ScriptFields(Script("double").Source("doc['n'].value * 2")),
+		},
+		{"empty search request", Search()},
+		{"search request with custom fields", Search().Size(5).Custom("track_total_hits", true).Custom("size", 10)},
+		{"short form search request", Search().Query(Bool().Must(Term("user", "kimchy"))).ShortForm(true)},
+		{"custom query", CustomQuery(map[string]interface{}{"match_all": map[string]interface{}{}})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exp, err := json.Marshal(test.node.Map())
+			assert.Nil(t, err)
+
+			var buf bytes.Buffer
+			assert.Nil(t, WriteJSON(&buf, test.node))
+			assert.Equal(t, string(exp), buf.String())
+
+			if w, ok := test.node.(JSONWriter); ok {
+				buf.Reset()
+				assert.Nil(t, w.WriteJSON(&buf))
+				assert.Equal(t, string(exp), buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteJSONUnsupportedValue(t *testing.T) {
+	var buf bytes.Buffer
+	err := KNN("embedding", []float64{math.NaN()}).K(1).WriteJSON(&buf)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, buf.Len())
+
+	_, err = Search().Query(Term("n", math.Inf(1))).MarshalJSON()
+	assert.NotNil(t, err)
+}
+
+func benchmarkVector() []float64 {
+	vector := make([]float64, 768)
+	for i := range vector {
+		vector[i] = float64(i) / 7
+	}
+	return vector
+}
+
+func benchmarkSearch() *SearchRequest {
+	return Search().
+		Query(Bool().
+			Must(KNN("embedding", benchmarkVector()).K(10)).
+			Filter(Term("tenant", "acme"), Range("date").Gte("now-7d")).
+			MustNot(Exists("deleted"))).
+		Highlight(Highlight().Field("title")).
+		Size(10)
+}
+
+func BenchmarkSearchMapJSON(b *testing.B) {
+	req := benchmarkSearch()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(req.Map()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchWriteJSON(b *testing.B) {
+	req := benchmarkSearch()
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := req.WriteJSON(&buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTermMapJSON(b *testing.B) {
+	q := Bool().Filter(Term("tenant", "acme"), Range("age").Gte(18).Lt(65))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(q.Map()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTermWriteJSON(b *testing.B) {
+	q := Bool().Filter(Term("tenant", "acme"), Range("age").Gte(18).Lt(65))
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := q.WriteJSON(&buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package osquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// Task is a handle on a request running asynchronously in the cluster, such
+// as a delete by query started with DeleteRequest.RunAsync, as described in
+// https://opensearch.org/docs/latest/api-reference/tasks/
+type Task struct {
+	// ID is the identifier of the task, in the "node:id" format.
+	ID string
+
+	// api is the API that started the task, used for rethrottling.
+	api string
+}
+
+// Status retrieves the current status of the task.
+func (t *Task) Status(ctx context.Context, client *opensearch.Client) (*TaskStatus, error) {
+	req := opensearchapi.TasksGetReq{
+		TaskID: t.ID,
+	}
+
+	var res TaskStatus
+	if err := execute(ctx, client, req, &res); err != nil {
+		return nil, fmt.Errorf("get task request failed: %w", err)
+	}
+
+	return &res, nil
+}
+
+// defaultTaskInterval is the interval at which Wait polls tasks when no
+// positive interval is provided.
+const defaultTaskInterval = time.Second
+
+// Wait polls the status of the task at the provided interval until it
+// completes or the context is done, and returns its final status. A
+// non-positive interval polls every second.
+func (t *Task) Wait(
+	ctx context.Context,
+	client *opensearch.Client,
+	interval time.Duration,
+) (*TaskStatus, error) {
+	if interval <= 0 {
+		interval = defaultTaskInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := t.Status(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		if status.Completed {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rethrottle changes the number of requests per second the task is allowed to
+// issue. A value of -1 disables throttling.
+func (t *Task) Rethrottle(
+	ctx context.Context,
+	client *opensearch.Client,
+	requestsPerSecond int,
+) error {
+	var req opensearch.Request
+	switch t.api {
+	case "delete_by_query":
+		req = opensearchapi.DocumentDeleteByQueryRethrottleReq{
+			TaskID: t.ID,
+			Params: opensearchapi.DocumentDeleteByQueryRethrottleParams{
+				RequestsPerSecond: &requestsPerSecond,
+			},
+		}
+	case "update_by_query":
+		req = opensearchapi.UpdateByQueryRethrottleReq{
+			TaskID: t.ID,
+			Params: opensearchapi.UpdateByQueryRethrottleParams{
+				RequestsPerSecond: &requestsPerSecond,
+			},
+		}
+	case "reindex":
+		req = opensearchapi.ReindexRethrottleReq{
+			TaskID: t.ID,
+			Params: opensearchapi.ReindexRethrottleParams{
+				RequestsPerSecond: &requestsPerSecond,
+			},
+		}
+	default:
+		return fmt.Errorf("task %s cannot be rethrottled", t.ID)
+	}
+
+	var res nodeFailures
+	if err := execute(ctx, client, req, &res); err != nil {
+		return fmt.Errorf("rethrottle request failed: %w", err)
+	}
+
+	return res.err()
+}
+
+// Cancel cancels the task. Cancellation is asynchronous; the task may still be
+// running when Cancel returns.
+func (t *Task) Cancel(ctx context.Context, client *opensearch.Client) error {
+	req := opensearchapi.TasksCancelReq{
+		TaskID: t.ID,
+	}
+
+	var res nodeFailures
+	if err := execute(ctx, client, req, &res); err != nil {
+		return fmt.Errorf("cancel task request failed: %w", err)
+	}
+
+	return res.err()
+}
+
+// nodeFailures holds the node failures reported by task management APIs
+// along with a successful status code.
+type nodeFailures struct {
+	NodeFailures []opensearchapi.FailuresCause `json:"node_failures"`
+}
+
+func (res nodeFailures) err() error {
+	if len(res.NodeFailures) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(res.NodeFailures))
+	for i, failure := range res.NodeFailures {
+		errs[i] = fmt.Errorf("%s: %s", failure.Type, failure.Reason)
+	}
+	return errors.Join(errs...)
+}
+
+//----------------------------------------------------------------------------//
+
+// TaskStatus is the status of a task, as returned by the tasks API. Once the
+// task is completed, Response holds the response the request would have
+// returned had it run synchronously, and Error the error it failed with, if
+// any.
+type TaskStatus struct {
+	Completed bool            `json:"completed"`
+	Task      TaskInfo        `json:"task"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     json.RawMessage `json:"error,omitempty"`
+}
+
+// TaskInfo describes a task.
+type TaskInfo struct {
+	Node               string          `json:"node"`
+	ID                 int64           `json:"id"`
+	Type               string          `json:"type"`
+	Action             string          `json:"action"`
+	Description        string          `json:"description"`
+	StartTimeInMillis  int64           `json:"start_time_in_millis"`
+	RunningTimeInNanos int64           `json:"running_time_in_nanos"`
+	Cancellable        bool            `json:"cancellable"`
+	Cancelled          bool            `json:"cancelled"`
+	Status             ByQueryProgress `json:"status"`
+}
+
+// ByQueryProgress is the progress of a delete by query, update by query or
+// reindex task.
+type ByQueryProgress struct {
+	Total            int64 `json:"total"`
+	Updated          int64 `json:"updated"`
+	Created          int64 `json:"created"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int64 `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+	Retries          struct {
+		Bulk   int64 `json:"bulk"`
+		Search int64 `json:"search"`
+	} `json:"retries"`
+	ThrottledMillis      int64   `json:"throttled_millis"`
+	RequestsPerSecond    float64 `json:"requests_per_second"`
+	ThrottledUntilMillis int64   `json:"throttled_until_millis"`
+}
@@ -0,0 +1,250 @@
+package osquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	opensearch "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// UpdateByQueryRequest represents a request to OpenSearch's Update By Query
+// API, described in
+// https://opensearch.org/docs/latest/api-reference/document-apis/update-by-query/
+type UpdateByQueryRequest struct {
+	index             []string
+	query             Mappable
+	script            *ScriptField
+	conflicts         Conflicts
+	slices            interface{}
+	requestsPerSecond *int
+	maxDocs           *int
+	pipeline          string
+	refresh           *bool
+}
+
+// UpdateByQuery creates a new UpdateByQueryRequest object, to be filled via
+// method chaining.
+func UpdateByQuery() *UpdateByQueryRequest {
+	return &UpdateByQueryRequest{}
+}
+
+// Index sets the index names for the request. They take precedence over the
+// indices of the options provided to Run.
+func (req *UpdateByQueryRequest) Index(index ...string) *UpdateByQueryRequest {
+	req.index = index
+	return req
+}
+
+// Query sets a query selecting the documents to update. All documents are
+// updated if no query is set.
+func (req *UpdateByQueryRequest) Query(q Mappable) *UpdateByQueryRequest {
+	req.query = q
+	return req
+}
+
+// Script sets the script used to update each document.
+func (req *UpdateByQueryRequest) Script(script *ScriptField) *UpdateByQueryRequest {
+	req.script = script
+	return req
+}
+
+// Conflicts sets what to do when a document changed while being updated. The
+// default is to abort the request.
+func (req *UpdateByQueryRequest) Conflicts(c Conflicts) *UpdateByQueryRequest {
+	req.conflicts = c
+	return req
+}
+
+// Slices sets the number of slices the request is divided into.
+func (req *UpdateByQueryRequest) Slices(n int) *UpdateByQueryRequest {
+	req.slices = n
+	return req
+}
+
+// AutoSlices lets OpenSearch choose the number of slices the request is
+// divided into.
+func (req *UpdateByQueryRequest) AutoSlices() *UpdateByQueryRequest {
+	req.slices = "auto"
+	return req
+}
+
+// RequestsPerSecond throttles the request to the provided number of requests
+// per second. A value of -1 disables throttling.
+func (req *UpdateByQueryRequest) RequestsPerSecond(n int) *UpdateByQueryRequest {
+	req.requestsPerSecond = &n
+	return req
+}
+
+// MaxDocs sets the maximum number of documents to update.
+func (req *UpdateByQueryRequest) MaxDocs(n int) *UpdateByQueryRequest {
+	req.maxDocs = &n
+	return req
+}
+
+// Pipeline sets the ingest pipeline documents are run through when updated.
+func (req *UpdateByQueryRequest) Pipeline(pipeline string) *UpdateByQueryRequest {
+	req.pipeline = pipeline
+	return req
+}
+
+// Refresh sets whether the affected shards are refreshed once the request
+// completes.
+func (req *UpdateByQueryRequest) Refresh(b bool) *UpdateByQueryRequest {
+	req.refresh = &b
+	return req
+}
+
+// Map returns a map representation of the request body, thus implementing the
+// Mappable interface.
+func (req *UpdateByQueryRequest) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if req.query != nil {
+		m["query"] = req.query.Map()
+	}
+	if req.script != nil {
+		m["script"] = scriptMap(req.script)
+	}
+	return m
+}
+
+// Run executes the request using the provided OpenSearch client, waiting for
+// it to complete. Values set on the request itself take precedence
+// over the options' Params.
+func (req *UpdateByQueryRequest) Run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *UpdateByQueryOptions,
+) (*BulkByScrollResponse, error) {
+	var res BulkByScrollResponse
+	if err := req.run(ctx, client, options, true, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// RunAsync starts the request using the provided OpenSearch client without
+// waiting for it to complete, and returns a handle on the task running it.
+func (req *UpdateByQueryRequest) RunAsync(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *UpdateByQueryOptions,
+) (*Task, error) {
+	var res struct {
+		Task string `json:"task"`
+	}
+	if err := req.run(ctx, client, options, false, &res); err != nil {
+		return nil, err
+	}
+
+	return &Task{ID: res.Task, api: "update_by_query"}, nil
+}
+
+func (req *UpdateByQueryRequest) run(
+	ctx context.Context,
+	client *opensearch.Client,
+	options *UpdateByQueryOptions,
+	wait bool,
+	out interface{},
+) error {
+	if err := validateQuery(req.query, "query"); err != nil {
+		return fmt.Errorf("invalid update by query request: %w", err)
+	}
+
+	// Serialize the request body to JSON
+	body, err := json.Marshal(req.Map())
+	if err != nil {
+		return fmt.Errorf("failed to serialize request body: %w", err)
+	}
+
+	updateReq := opensearchapi.UpdateByQueryReq{
+		Body: bytes.NewReader(body),
+	}
+
+	// Apply additional options if provided
+	options.Apply(&updateReq.Indices, &updateReq.Header, &updateReq.Params)
+
+	if req.index != nil {
+		updateReq.Indices = req.index
+	}
+	if len(updateReq.Indices) == 0 {
+		return errors.New("update by query requires at least one index")
+	}
+
+	params := &updateReq.Params
+	if req.conflicts != "" {
+		params.Conflicts = string(req.conflicts)
+	}
+	if req.slices != nil {
+		params.Slices = req.slices
+	}
+	if req.requestsPerSecond != nil {
+		params.RequestsPerSecond = req.requestsPerSecond
+	}
+	if req.maxDocs != nil {
+		params.MaxDocs = req.maxDocs
+	}
+	if req.pipeline != "" {
+		params.Pipeline = req.pipeline
+	}
+	if req.refresh != nil {
+		params.Refresh = req.refresh
+	}
+	if !wait {
+		params.WaitForCompletion = &wait
+	}
+
+	if err := execute(ctx, client, updateReq, out); err != nil {
+		return fmt.Errorf("update by query request failed: %w", err)
+	}
+
+	return nil
+}
+
+//----------------------------------------------------------------------------//
+
+// BulkByScrollResponse is the response of an update by query or reindex
+// request that ran to completion. The same response is available through
+// TaskStatus.Response for requests started asynchronously.
+type BulkByScrollResponse struct {
+	Took             int64 `json:"took"`
+	TimedOut         bool  `json:"timed_out"`
+	Total            int64 `json:"total"`
+	Updated          int64 `json:"updated"`
+	Created          int64 `json:"created"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int64 `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+	Retries          struct {
+		Bulk   int64 `json:"bulk"`
+		Search int64 `json:"search"`
+	} `json:"retries"`
+	ThrottledMillis      int64                 `json:"throttled_millis"`
+	RequestsPerSecond    float64               `json:"requests_per_second"`
+	ThrottledUntilMillis int64                 `json:"throttled_until_millis"`
+	Failures             []BulkByScrollFailure `json:"failures"`
+}
+
+// BulkByScrollFailure is a failure of an update by query or reindex request.
+// Indexing failures carry the ID of the document and a Cause, while search
+// failures carry the shard and node that failed and a Reason.
+type BulkByScrollFailure struct {
+	Index  string        `json:"index"`
+	ID     string        `json:"id,omitempty"`
+	Status int           `json:"status,omitempty"`
+	Cause  *FailureCause `json:"cause,omitempty"`
+	Shard  *int          `json:"shard,omitempty"`
+	Node   string        `json:"node,omitempty"`
+	Reason *FailureCause `json:"reason,omitempty"`
+}
+
+// FailureCause describes the cause of a failure.
+type FailureCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,101 @@
+package osquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestUpdateByQuery(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"update by query with a script",
+			UpdateByQuery().
+				Index("posts").
+				Query(Exists("legacy_tag")).
+				Script(Script("").
+					Source("ctx._source.tag = ctx._source.remove('legacy_tag')").
+					Lang("painless")),
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"exists": map[string]interface{}{
+						"field": "legacy_tag",
+					},
+				},
+				"script": map[string]interface{}{
+					"source": "ctx._source.tag = ctx._source.remove('legacy_tag')",
+					"lang":   "painless",
+				},
+			},
+		},
+		{
+			"update by query without query or script",
+			UpdateByQuery().Pipeline("set-timestamp"),
+			map[string]interface{}{},
+		},
+	})
+}
+
+func TestUpdateByQueryRun(t *testing.T) {
+	client, requests := newTestClient(t, `{
+		"took": 147,
+		"timed_out": false,
+		"total": 5,
+		"updated": 3,
+		"batches": 1,
+		"version_conflicts": 1,
+		"noops": 0,
+		"retries": {"bulk": 0, "search": 0},
+		"failures": [
+			{"index": "posts", "id": "4", "status": 409, "cause": {"type": "version_conflict_engine_exception", "reason": "version conflict"}},
+			{"shard": 0, "index": "posts", "node": "abc", "reason": {"type": "script_exception", "reason": "runtime error"}}
+		]
+	}`)
+
+	res, err := UpdateByQuery().
+		Index("posts").
+		Query(MatchAll()).
+		Script(Script("").Source("ctx._source.views++")).
+		Conflicts(ConflictsProceed).
+		Slices(2).
+		MaxDocs(10).
+		Pipeline("set-timestamp").
+		Refresh(true).
+		Run(context.Background(), client, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), res.Updated)
+	assert.Equal(t, int64(1), res.VersionConflicts)
+	assert.Equal(t, 2, len(res.Failures))
+	assert.Equal(t, "4", res.Failures[0].ID)
+	assert.Equal(t, "version_conflict_engine_exception", res.Failures[0].Cause.Type)
+	assert.Equal(t, 0, *res.Failures[1].Shard)
+	assert.Equal(t, "script_exception", res.Failures[1].Reason.Type)
+
+	assert.Equal(t, 1, len(*requests))
+	assert.Equal(t, "/posts/_update_by_query", (*requests)[0].path)
+	assert.Equal(t,
+		"conflicts=proceed&max_docs=10&pipeline=set-timestamp&refresh=true&slices=2",
+		(*requests)[0].query,
+	)
+	assert.Equal(t,
+		`{"query":{"match_all":{}},"script":{"source":"ctx._source.views++"}}`,
+		(*requests)[0].body,
+	)
+}
+
+func TestUpdateByQueryRunAsync(t *testing.T) {
+	client, requests := newTestClient(t,
+		`{"task": "abc:42"}`,
+		`{"nodes": {}}`,
+	)
+
+	ctx := context.Background()
+	task, err := UpdateByQuery().Index("posts").RequestsPerSecond(100).RunAsync(ctx, client, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc:42", task.ID)
+	assert.Equal(t, "requests_per_second=100&wait_for_completion=false", (*requests)[0].query)
+
+	assert.Nil(t, task.Rethrottle(ctx, client, 500))
+	assert.Equal(t, "/_update_by_query/abc:42/_rethrottle", (*requests)[1].path)
+}
@@ -0,0 +1,129 @@
+package osquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes a problem found in a query, aggregation or
+// request before it is sent to OpenSearch. Path locates the offending node
+// within the validated tree, such as "query.bool.must[1].range.age" or
+// "aggs.tags.terms".
+type ValidationError struct {
+	Path   string
+	Reason string
+}
+
+// Error implements the error interface.
+func (err *ValidationError) Error() string {
+	if err.Path == "" {
+		return err.Reason
+	}
+	return err.Path + ": " + err.Reason
+}
+
+// ValidationErrors holds all the problems found while validating a tree of
+// queries and aggregations. The Validate methods of the library's types return
+// a ValidationErrors value if any problem was found.
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs *ValidationErrors) add(path, format string, args ...interface{}) {
+	*errs = append(*errs, &ValidationError{
+		Path:   path,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+// validator is implemented by the query and aggregation types that can check
+// their parameters, recording the problems they find under the provided path.
+// Compound types validate their children as well.
+type validator interface {
+	validate(path string, errs *ValidationErrors)
+}
+
+// validate validates the tree rooted at node, returning nil if no problem was
+// found.
+func validate(node validator, path string) error {
+	var errs ValidationErrors
+	node.validate(path, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateQuery validates a query of a request, such as the query of a count
+// request. Queries not implementing validation, like custom queries, are
+// considered valid.
+func validateQuery(q Mappable, path string) error {
+	v, ok := q.(validator)
+	if !ok {
+		return nil
+	}
+	return validate(v, path)
+}
+
+// validateNode validates a child node, if its type supports validation.
+func validateNode(node Mappable, path string, errs *ValidationErrors) {
+	if v, ok := node.(validator); ok {
+		v.validate(path, errs)
+	}
+}
+
+// validateQueries validates a list of child queries, such as a clause of a
+// bool query.
+func validateQueries(queries []Mappable, path string, errs *ValidationErrors) {
+	for i, q := range queries {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if q == nil {
+			errs.add(itemPath, "query is nil")
+			continue
+		}
+		validateNode(q, itemPath, errs)
+	}
+}
+
+// validateAggs validates a list of aggregations, which must have unique
+// names.
+func validateAggs(aggs []Aggregation, path string, errs *ValidationErrors) {
+	seen := make(map[string]bool, len(aggs))
+	for i, agg := range aggs {
+		if agg == nil {
+			errs.add(fmt.Sprintf("%s[%d]", path, i), "aggregation is nil")
+			continue
+		}
+		aggPath := joinPath(path, agg.Name())
+		if seen[agg.Name()] {
+			errs.add(aggPath, "duplicate aggregation name")
+		}
+		seen[agg.Name()] = true
+		validateNode(agg, aggPath, errs)
+	}
+}
+
+// validateScript validates a script required by a query, aggregation or
+// request.
+func validateScript(script *ScriptField, path string, errs *ValidationErrors) {
+	switch {
+	case script == nil:
+		errs.add(path, "script is required")
+	case script.Src == "" && script.Id == "":
+		errs.add(path, "script requires a source or an id")
+	}
+}
+
+func joinPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
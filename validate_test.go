@@ -0,0 +1,191 @@
+package osquery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		node interface{ Validate() error }
+		exp  string
+	}{
+		{
+			"valid search request",
+			Search().
+				Query(Bool().Filter(Range("age").Gte(18), Term("status", "active"))).
+				Aggs(TermsAgg("tags", "tags").Include("a")),
+			"",
+		},
+		{
+			"empty bool query",
+			Bool(),
+			"bool: bool query has no clauses",
+		},
+		{
+			"range without bounds in a bool clause",
+			Bool().Must(Term("status", "active"), Range("age")),
+			"bool.must[1].range.age: range query has no bounds",
+		},
+		{
+			"nil clause",
+			Bool().Should(nil),
+			"bool.should[0]: query is nil",
+		},
+		{
+			"knn without k",
+			KNN("embedding", []float64{0.1, 0.2}),
+			"knn.embedding: one of k, max_distance or min_score is required",
+		},
+		{
+			"knn with both k and max distance",
+			KNN("embedding", []float64{0.1}).K(3).MaxDistance(2),
+			"knn.embedding: only one of k, max_distance or min_score can be set",
+		},
+		{
+			"knn without vector and with negative k",
+			KNN("embedding", nil).K(-1),
+			"knn.embedding: vector is required; knn.embedding: k must be positive, got -1",
+		},
+		{
+			"boosting without negative query",
+			Boosting().Positive(Range("price")),
+			"boosting.positive.range.price: range query has no bounds; boosting: negative query is required",
+		},
+		{
+			"bucket script without script",
+			BucketScriptAgg("ratio", map[string]string{"a": "a"}, nil),
+			"ratio.bucket_script: script is required",
+		},
+		{
+			"bucket selector with empty script",
+			BucketSelectorAgg("big", map[string]string{"a": "a"}, Script("")),
+			"big.bucket_selector: script requires a source or an id",
+		},
+		{
+			"script score function without script",
+			FunctionScore(MatchAll()).Functions(ScriptScoreFunction(nil)),
+			"function_score.functions[0].script_score: script is required",
+		},
+		{
+			"decay function without field",
+			FunctionScore(MatchAll()).Functions(WeightFunction(2), GaussFunction("", "now", "10d")),
+			"function_score.functions[1].gauss: field is required",
+		},
+		{
+			"decay function without scale",
+			FunctionScore(MatchAll()).Functions(ExpFunction("price", 0, nil)),
+			"function_score.functions[0].exp: scale is required",
+		},
+		{
+			"script score query without script",
+			ScriptScore(MatchAll(), nil),
+			"script_score: script requires a source or an id",
+		},
+		{
+			"constant score without filter",
+			ConstantScore(nil),
+			"constant_score: filter is required",
+		},
+		{
+			"empty dis max",
+			DisMax(),
+			"dis_max: dis_max query has no queries",
+		},
+		{
+			"nested without path",
+			Nested("", Bool()),
+			"nested: path is required; nested.query.bool: bool query has no clauses",
+		},
+		{
+			"script score without script",
+			ScriptScore(MatchAll(), Script("")),
+			"script_score: script requires a source or an id",
+		},
+		{
+			"empty terms include",
+			TermsAgg("tags", "tags").Include([]string{}...),
+			"tags.terms: include must not be empty",
+		},
+		{
+			"date histogram without interval",
+			DateHistogramAgg("per_day", "@timestamp"),
+			"per_day.date_histogram: calendar_interval or fixed_interval is required",
+		},
+		{
+			"histogram with zero interval",
+			HistogramAgg("prices", "price", 0),
+			"prices.histogram: interval must be positive, got 0",
+		},
+		{
+			"range aggregation without ranges",
+			RangeAgg("ages", "age"),
+			"ages.range: at least one range is required",
+		},
+		{
+			"filter aggregation with invalid filter and sub-aggregation",
+			FilterAgg("active", Range("age")).Aggs(NestedAgg("comments", "")),
+			"active.filter.range.age: range query has no bounds; active.aggs.comments.nested: path is required",
+		},
+		{
+			"search request",
+			Search().
+				Query(Bool()).
+				PostFilter(Range("price")).
+				Aggs(Avg("avg_price", "price"), Avg("avg_price", "price")),
+			"query.bool: bool query has no clauses; post_filter.range.price: range query has no bounds; aggs.avg_price: duplicate aggregation name",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.node.Validate()
+			if test.exp == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.NotNil(t, err)
+			assert.Equal(t, test.exp, err.Error())
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := Search().Query(Bool().Filter(Range("age"), Bool())).Validate()
+
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "query.bool.filter[0].range.age", errs[0].Path)
+	assert.Equal(t, "range query has no bounds", errs[0].Reason)
+	assert.Equal(t, "query.bool.filter[1].bool", errs[1].Path)
+	assert.Equal(t, "bool query has no clauses", errs[1].Reason)
+}
+
+func TestRunValidates(t *testing.T) {
+	client, requests := newTestClient(t)
+
+	_, err := Search().Query(Range("age")).Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid search request: query.range.age: range query has no bounds", err.Error())
+
+	_, err = Count(Bool()).Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid count request: query.bool: bool query has no clauses", err.Error())
+
+	_, err = MultiSearch().
+		Add(Search(), nil).
+		Add(Search().Aggs(TermsAgg("tags", "tags").Include([]string{}...)), nil).
+		Run(context.Background(), client, nil)
+	assert.NotNil(t, err)
+
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, "aggs.tags.terms", errs[0].Path)
+
+	assert.Equal(t, 0, len(*requests))
+}
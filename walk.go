@@ -0,0 +1,483 @@
+package osquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Walk traverses a tree of queries and aggregations in depth-first order,
+// starting with node. It calls fn for every node of the tree; if fn returns
+// false, the children of that node are skipped.
+//
+// The node may be a *SearchRequest, whose children are its query, post filter
+// and aggregations, a query, or an aggregation. The children of compound
+// queries are their sub-queries, such as the clauses of a bool query or the
+// filters of the functions of a function score query. The children of
+// aggregations are the filter of filter aggregations, followed by their
+// sub-aggregations. Custom queries and aggregations are leaves.
+func Walk(node Mappable, fn func(node Mappable) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Walk(child, fn)
+	}
+}
+
+// children returns the child nodes of a node, in the order they are walked.
+func children(node Mappable) []Mappable {
+	var nodes []Mappable
+	add := func(qs ...Mappable) {
+		for _, q := range qs {
+			if q != nil {
+				nodes = append(nodes, q)
+			}
+		}
+	}
+	addAggs := func(aggs []Aggregation) {
+		for _, agg := range aggs {
+			add(agg)
+		}
+	}
+
+	switch n := node.(type) {
+	case *SearchRequest:
+		add(n.query, n.postFilter)
+		addAggs(n.aggs)
+	case *BoolQuery:
+		add(n.must...)
+		add(n.filter...)
+		add(n.mustNot...)
+		add(n.should...)
+	case *BoostingQuery:
+		add(n.Pos, n.Neg)
+	case *ConstantScoreQuery:
+		add(n.filter)
+	case *DisMaxQuery:
+		add(n.queries...)
+	case *NestedQuery:
+		add(n.query)
+	case *ScriptScoreQuery:
+		add(n.query)
+	case *FunctionScoreQuery:
+		add(n.query)
+		for _, fn := range n.functions {
+			add(fn.filter)
+		}
+	case *FilterAggregation:
+		add(n.filter)
+		addAggs(n.aggs)
+	case *NestedAggregation:
+		addAggs(n.aggs)
+	case *TermsAggregation:
+		addAggs(n.aggs)
+	case *DateHistogramAggregation:
+		addAggs(n.aggs)
+	case *HistogramAggregation:
+		addAggs(n.aggs)
+	case *RangeAggregation:
+		addAggs(n.aggs)
+	case *CompositeAggregation:
+		addAggs(n.aggs)
+	}
+	return nodes
+}
+
+//----------------------------------------------------------------------------//
+
+// Transform rewrites a tree of queries and aggregations, as traversed by
+// Walk. Children are transformed before their parent, and fn is then called
+// with every node; the node is replaced by the returned value. Nodes with
+// children are passed to fn as copies holding the transformed children, so the
+// provided tree is not modified unless fn modifies the leaves it is passed.
+//
+// Returning nil removes the node from its parent. Removing an optional child,
+// such as a clause of a bool query or the query of a search request, leaves the
+// parent in place, while removing a required child, such as the filter of a
+// constant_score query, removes the parent as well. Note that removing clauses
+// may widen the set of matching documents: a bool query with no clause left
+// matches all documents.
+//
+// Aggregations may only be replaced by other aggregations; an error is
+// returned otherwise.
+func Transform(node Mappable, fn func(node Mappable) Mappable) (Mappable, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	node, err := transformChildren(node, fn)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	return fn(node), nil
+}
+
+// transformChildren returns a copy of node holding its transformed children,
+// or node itself if it has no children. It returns nil if a required child
+// was removed.
+func transformChildren(node Mappable, fn func(Mappable) Mappable) (Mappable, error) {
+	var err error
+	switch n := node.(type) {
+	case *SearchRequest:
+		c := *n
+		if c.query, err = Transform(c.query, fn); err != nil {
+			return nil, err
+		}
+		if c.postFilter, err = Transform(c.postFilter, fn); err != nil {
+			return nil, err
+		}
+		if c.aggs, err = transformAggs(c.aggs, fn); err != nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *BoolQuery:
+		c := *n
+		for _, clause := range []*[]Mappable{&c.must, &c.filter, &c.mustNot, &c.should} {
+			if *clause, err = transformQueries(*clause, fn); err != nil {
+				return nil, err
+			}
+		}
+		return &c, nil
+
+	case *BoostingQuery:
+		c := *n
+		if c.Pos, err = Transform(c.Pos, fn); err != nil || c.Pos == nil {
+			return nil, err
+		}
+		if c.Neg, err = Transform(c.Neg, fn); err != nil || c.Neg == nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *ConstantScoreQuery:
+		c := *n
+		if c.filter, err = Transform(c.filter, fn); err != nil || c.filter == nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *DisMaxQuery:
+		c := *n
+		if c.queries, err = transformQueries(c.queries, fn); err != nil || len(c.queries) == 0 {
+			return nil, err
+		}
+		return &c, nil
+
+	case *NestedQuery:
+		c := *n
+		if c.query, err = Transform(c.query, fn); err != nil || c.query == nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *ScriptScoreQuery:
+		c := *n
+		if c.query, err = Transform(c.query, fn); err != nil || c.query == nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *FunctionScoreQuery:
+		c := *n
+		if c.query, err = Transform(c.query, fn); err != nil {
+			return nil, err
+		}
+		c.functions = make([]*ScoreFunction, len(n.functions))
+		for i, sf := range n.functions {
+			sfCopy := *sf
+			if sfCopy.filter, err = Transform(sf.filter, fn); err != nil {
+				return nil, err
+			}
+			c.functions[i] = &sfCopy
+		}
+		return &c, nil
+
+	case *FilterAggregation:
+		c := *n
+		if c.filter, err = Transform(c.filter, fn); err != nil || c.filter == nil {
+			return nil, err
+		}
+		if c.aggs, err = transformAggs(c.aggs, fn); err != nil {
+			return nil, err
+		}
+		return &c, nil
+
+	case *NestedAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+
+	case *TermsAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+
+	case *DateHistogramAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+
+	case *HistogramAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+
+	case *RangeAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+
+	case *CompositeAggregation:
+		c := *n
+		c.aggs, err = transformAggs(c.aggs, fn)
+		return &c, err
+	}
+
+	return node, nil
+}
+
+// transformQueries transforms a list of queries, dropping removed ones.
+func transformQueries(queries []Mappable, fn func(Mappable) Mappable) ([]Mappable, error) {
+	if len(queries) == 0 {
+		return queries, nil
+	}
+
+	res := make([]Mappable, 0, len(queries))
+	for _, q := range queries {
+		q, err := Transform(q, fn)
+		if err != nil {
+			return nil, err
+		}
+		if q != nil {
+			res = append(res, q)
+		}
+	}
+	return res, nil
+}
+
+// transformAggs transforms a list of aggregations, dropping removed ones.
+func transformAggs(aggs []Aggregation, fn func(Mappable) Mappable) ([]Aggregation, error) {
+	if len(aggs) == 0 {
+		return aggs, nil
+	}
+
+	res := make([]Aggregation, 0, len(aggs))
+	for _, agg := range aggs {
+		node, err := Transform(agg, fn)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue
+		}
+		replaced, ok := node.(Aggregation)
+		if !ok {
+			return nil, fmt.Errorf("aggregation %q replaced by %T, which is not an aggregation", agg.Name(), node)
+		}
+		res = append(res, replaced)
+	}
+	return res, nil
+}
+
+//----------------------------------------------------------------------------//
+
+// RenameFields returns a copy of a tree of queries and aggregations, as
+// traversed by Walk, with the fields its queries and aggregations target
+// renamed by the provided function. This includes the fields of term-level,
+// full-text, query_string and knn queries, the paths of nested queries and
+// aggregations, the fields of metric and bucket aggregations, including the
+// value and weight fields of weighted_avg aggregations, and the fields and
+// nested paths of the field sorts of search requests. Boosts of fields, as in
+// "title^2", are preserved. Metadata fields of sorts, such as "_score", custom
+// queries, aggregations and sorts, scripts, the sources of composite
+// aggregations and the sort and source settings of top_hits aggregations are
+// left untouched.
+func RenameFields(node Mappable, rename func(field string) string) (Mappable, error) {
+	return Transform(node, func(node Mappable) Mappable {
+		return renameField(node, rename)
+	})
+}
+
+// renameField returns a copy of node with its field renamed, or node itself if
+// it does not target a field.
+func renameField(node Mappable, rename func(string) string) Mappable {
+	switch n := node.(type) {
+	case *ExistsQuery:
+		return Exists(rename(n.Field))
+	case *PrefixQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *RangeQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *RegexpQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *FuzzyQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *TermQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *TermsQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *TermsSetQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *MatchQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *MultiMatchQuery:
+		c := *n
+		c.params.Fields = renameBoostedFields(n.params.Fields, rename)
+		return &c
+	case *QueryStringQuery:
+		c := *n
+		c.params.Fields = renameBoostedFields(n.params.Fields, rename)
+		if c.params.DefaultField != "" {
+			c.params.DefaultField = rename(c.params.DefaultField)
+		}
+		return &c
+	case *SimpleQueryStringQuery:
+		c := *n
+		c.params.Fields = renameBoostedFields(n.params.Fields, rename)
+		return &c
+	case *KNNQuery:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *NestedQuery:
+		c := *n
+		c.path = rename(c.path)
+		return &c
+
+	case *SearchRequest:
+		// Transform passes a copy of the request
+		if n.sort != nil {
+			sort := make([]SortOption, len(n.sort))
+			for i, opt := range n.sort {
+				sort[i] = renameSort(opt, rename)
+			}
+			n.sort = sort
+		}
+		return n
+
+	case *NestedAggregation:
+		c := *n
+		c.path = rename(c.path)
+		return &c
+	case *TermsAggregation:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *DateHistogramAggregation:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *HistogramAggregation:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *RangeAggregation:
+		c := *n
+		c.field = rename(c.field)
+		return &c
+	case *AvgAgg:
+		return &AvgAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *MaxAgg:
+		return &MaxAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *MinAgg:
+		return &MinAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *SumAgg:
+		return &SumAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *ValueCountAgg:
+		return &ValueCountAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *StatsAgg:
+		return &StatsAgg{BaseAgg: renameBaseAgg(n.BaseAgg, rename)}
+	case *CardinalityAgg:
+		c := *n
+		c.BaseAgg = renameBaseAgg(n.BaseAgg, rename)
+		return &c
+	case *PercentilesAgg:
+		c := *n
+		c.BaseAgg = renameBaseAgg(n.BaseAgg, rename)
+		return &c
+	case *StringStatsAgg:
+		c := *n
+		c.BaseAgg = renameBaseAgg(n.BaseAgg, rename)
+		return &c
+	case *WeightedAvgAgg:
+		c := *n
+		c.Val = renameAggParams(n.Val, rename)
+		c.Weig = renameAggParams(n.Weig, rename)
+		return &c
+	}
+	return node
+}
+
+// renameBoostedFields returns a copy of a list of fields that may carry a
+// boost, such as "title^2", with the fields renamed.
+func renameBoostedFields(fields []string, rename func(string) string) []string {
+	if fields == nil {
+		return nil
+	}
+	renamed := make([]string, len(fields))
+	for i, field := range fields {
+		name, boost, boosted := strings.Cut(field, "^")
+		renamed[i] = rename(name)
+		if boosted {
+			renamed[i] += "^" + boost
+		}
+	}
+	return renamed
+}
+
+// renameSort returns a copy of a field sort with its field, nested path and
+// nested filter renamed. Other sort options are returned as is.
+func renameSort(opt SortOption, rename func(string) string) SortOption {
+	f, ok := opt.(*FieldSortOption)
+	if !ok {
+		return opt
+	}
+	c := *f
+	if !strings.HasPrefix(c.field, "_") {
+		c.field = rename(c.field)
+	}
+	if c.nestedPath != "" {
+		c.nestedPath = rename(c.nestedPath)
+	}
+	if c.nestedFilter != nil {
+		// renaming queries cannot fail, only replacing aggregations can
+		c.nestedFilter, _ = RenameFields(c.nestedFilter, rename)
+	}
+	return &c
+}
+
+// renameBaseAgg returns a copy of the base of a metric aggregation with its
+// field renamed.
+func renameBaseAgg(agg *BaseAgg, rename func(string) string) *BaseAgg {
+	c := *agg
+	c.BaseAggParams = renameAggParams(agg.BaseAggParams, rename)
+	return &c
+}
+
+// renameAggParams returns a copy of the parameters of a metric aggregation
+// with their field renamed.
+func renameAggParams(params *BaseAggParams, rename func(string) string) *BaseAggParams {
+	if params == nil {
+		return nil
+	}
+	c := *params
+	c.Field = rename(c.Field)
+	return &c
+}
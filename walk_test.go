@@ -0,0 +1,265 @@
+package osquery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestWalk(t *testing.T) {
+	req := Search().
+		Query(Bool().
+			Must(Match("title", "go")).
+			Filter(
+				Nested("comments", Term("comments.author", "bob")),
+				ConstantScore(Exists("tag")),
+			)).
+		PostFilter(Term("tag", "tech")).
+		Aggs(
+			FilterAgg("recent", Range("@timestamp").Gte("now-1d")).
+				Aggs(TermsAgg("tags", "tag").Aggs(Avg("avg_views", "views"))),
+			CustomAgg("custom", map[string]interface{}{"max": map[string]interface{}{"field": "views"}}),
+		)
+
+	var visited []string
+	Walk(req, func(node Mappable) bool {
+		visited = append(visited, typeName(node))
+		_, nested := node.(*NestedQuery)
+		return !nested
+	})
+
+	assert.DeepEqual(t, []string{
+		"*osquery.SearchRequest",
+		"*osquery.BoolQuery",
+		"*osquery.MatchQuery",
+		"*osquery.NestedQuery",
+		"*osquery.ConstantScoreQuery",
+		"*osquery.ExistsQuery",
+		"*osquery.TermQuery",
+		"*osquery.FilterAggregation",
+		"*osquery.RangeQuery",
+		"*osquery.TermsAggregation",
+		"*osquery.AvgAgg",
+		"*osquery.CustomAggMap",
+	}, visited)
+}
+
+func TestTransform(t *testing.T) {
+	q := Bool().
+		Must(Match("title", "go")).
+		Should(Wildcard("title", "*go*"), Term("tag", "go")).
+		Filter(ConstantScore(Wildcard("body", "*go*")))
+	before := q.Map()
+
+	// strip wildcard queries, which also removes the constant_score query
+	// they are the filter of, then inject a tenant filter at the root
+	res, err := Transform(q, func(node Mappable) Mappable {
+		if _, ok := node.(*RegexpQuery); ok {
+			return nil
+		}
+		return node
+	})
+	assert.Nil(t, err)
+	res = Bool().Must(res).Filter(Term("tenant", "acme"))
+
+	runMapTests(t, []mapTest{
+		{
+			"disallowed clauses stripped",
+			res,
+			map[string]interface{}{
+				"bool": map[string]interface{}{
+					"must": []map[string]interface{}{
+						{
+							"bool": map[string]interface{}{
+								"must": []map[string]interface{}{
+									{"match": map[string]interface{}{"title": map[string]interface{}{"query": "go"}}},
+								},
+								"should": []map[string]interface{}{
+									{"term": map[string]interface{}{"tag": map[string]interface{}{"value": "go"}}},
+								},
+							},
+						},
+					},
+					"filter": []map[string]interface{}{
+						{"term": map[string]interface{}{"tenant": map[string]interface{}{"value": "acme"}}},
+					},
+				},
+			},
+		},
+		{
+			"original left untouched",
+			q,
+			before,
+		},
+	})
+}
+
+func TestTransformAggs(t *testing.T) {
+	req := Search().Aggs(
+		TermsAgg("tags", "tag").Aggs(Avg("avg_views", "views"), Max("max_views", "views")),
+		FilterAgg("go", Term("tag", "go")),
+	)
+
+	res, err := Transform(req, func(node Mappable) Mappable {
+		switch n := node.(type) {
+		case *MaxAgg:
+			return nil
+		case *TermQuery:
+			// removing the filter of a filter aggregation removes the aggregation
+			if n.field == "tag" {
+				return nil
+			}
+		}
+		return node
+	})
+	assert.Nil(t, err)
+
+	runMapTests(t, []mapTest{
+		{
+			"aggregations removed",
+			res,
+			map[string]interface{}{
+				"aggs": map[string]interface{}{
+					"tags": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "tag"},
+						"aggs": map[string]interface{}{
+							"avg_views": map[string]interface{}{"avg": map[string]interface{}{"field": "views"}},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	_, err = Transform(req, func(node Mappable) Mappable {
+		if _, ok := node.(*AvgAgg); ok {
+			return Term("views", 1)
+		}
+		return node
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, `aggregation "avg_views" replaced by *osquery.TermQuery, which is not an aggregation`, err.Error())
+}
+
+func TestRenameFields(t *testing.T) {
+	req := Search().
+		Query(Bool().
+			Must(MultiMatch("go").Fields("title^2", "body")).
+			Filter(Range("created").Gte("now-1d"), Nested("comments", Term("comments.author", "bob")))).
+		Aggs(
+			TermsAgg("tags", "tag").Aggs(Cardinality("authors", "author").PrecisionThreshold(100)),
+		)
+	before := req.Map()
+
+	res, err := RenameFields(req, func(field string) string {
+		if field == "tag" || strings.HasPrefix(field, "comments") {
+			return "v2_" + field
+		}
+		return field + "_v2"
+	})
+	assert.Nil(t, err)
+
+	runMapTests(t, []mapTest{
+		{
+			"fields renamed",
+			res,
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"must": []map[string]interface{}{
+							{"multi_match": map[string]interface{}{
+								"query":  "go",
+								"fields": []string{"title_v2^2", "body_v2"},
+							}},
+						},
+						"filter": []map[string]interface{}{
+							{"range": map[string]interface{}{"created_v2": map[string]interface{}{"gte": "now-1d"}}},
+							{"nested": map[string]interface{}{
+								"path": "v2_comments",
+								"query": map[string]interface{}{
+									"term": map[string]interface{}{"v2_comments.author": map[string]interface{}{"value": "bob"}},
+								},
+							}},
+						},
+					},
+				},
+				"aggs": map[string]interface{}{
+					"tags": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "v2_tag"},
+						"aggs": map[string]interface{}{
+							"authors": map[string]interface{}{
+								"cardinality": map[string]interface{}{"field": "author_v2", "precision_threshold": 100},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"original left untouched",
+			req,
+			before,
+		},
+	})
+}
+
+func TestRenameFieldsKNNQueryStringAndSort(t *testing.T) {
+	req := Search().
+		Query(Bool().
+			Must(KNN("old_vec", []float64{1}).K(2), MultiMatch("go")).
+			Should(
+				QueryString("go").Fields("old_title^2").DefaultField("old_body"),
+				SimpleQueryString("go").Fields("old_title"),
+			)).
+		Sort(
+			FieldSort("old_d"),
+			FieldSort("_score"),
+			FieldSort("old_c.likes").NestedPath("old_c").NestedFilter(Term("old_c.author", "bob")),
+		)
+
+	res, err := RenameFields(req, func(field string) string {
+		return strings.Replace(field, "old_", "new_", 1)
+	})
+	assert.Nil(t, err)
+
+	assertJSON(t, `{
+		"query": {"bool": {
+			"must": [
+				{"knn": {"new_vec": {"vector": [1], "k": 2}}},
+				{"multi_match": {"query": "go", "fields": null}}
+			],
+			"should": [
+				{"query_string": {"query": "go", "fields": ["new_title^2"], "default_field": "new_body"}},
+				{"simple_query_string": {"query": "go", "fields": ["new_title"]}}
+			]
+		}},
+		"sort": [
+			{"new_d": {}},
+			{"_score": {}},
+			{"new_c.likes": {"nested_path": "new_c", "nested_filter": {"term": {"new_c.author": {"value": "bob"}}}}}
+		]
+	}`, res.Map())
+
+	// fields left unset are not added
+	mm := res.(*SearchRequest).query.(*BoolQuery).must[1].(*MultiMatchQuery)
+	assert.True(t, mm.params.Fields == nil)
+
+	// the original sort is left untouched
+	assertJSON(t, `{"old_d": {}}`, req.sort[0].Map())
+}
+
+func TestRenameFieldsWeightedAvg(t *testing.T) {
+	agg := WeightedAvg("avg_grade").Value("grade", 0).Weight("weight")
+
+	res, err := RenameFields(agg, func(field string) string { return field + "_v2" })
+	assert.Nil(t, err)
+	assertJSON(t,
+		`{"weighted_avg": {"value": {"field": "grade_v2", "missing": 0}, "weight": {"field": "weight_v2"}}}`,
+		res.Map(),
+	)
+	assertJSON(t,
+		`{"weighted_avg": {"value": {"field": "grade", "missing": 0}, "weight": {"field": "weight"}}}`,
+		agg.Map(),
+	)
+}